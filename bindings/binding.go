@@ -17,12 +17,17 @@
 package bindings
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/nebhale/client-go/internal"
 )
@@ -33,6 +38,26 @@ const Provider = "provider"
 // Type is the key for the type of a binding.
 const Type = "type"
 
+// ErrInvalidKey is returned when a key is not a valid Kubernetes Secret key.
+var ErrInvalidKey = errors.New("invalid key")
+
+// ErrNotFound is returned when a binding does not contain a key.
+var ErrNotFound = errors.New("key not found")
+
+// ErrNotRegularFile is returned when a binding entry exists but is not a regular file.
+var ErrNotRegularFile = errors.New("not a regular file")
+
+// ErrOutsideRoot is returned when a binding entry resolves to a file outside the binding root and Restrictions.Confine
+// is set.
+var ErrOutsideRoot = errors.New("resolves outside binding root")
+
+// ErrTooLarge is returned when a binding entry is larger than Restrictions.MaxSize.
+var ErrTooLarge = errors.New("exceeds maximum entry size")
+
+// ErrWorldReadable is returned when a binding entry is readable by all users and Restrictions.RejectWorldReadable is
+// set.
+var ErrWorldReadable = errors.New("world-readable")
+
 // Binding is representation of a binding as defined by the Kubernetes Service Binding Specification:
 // https://github.com/k8s-service-bindings/spec#workload-projection.
 type Binding interface {
@@ -44,6 +69,23 @@ type Binding interface {
 	GetName() string
 }
 
+// KeyedBinding is a Binding that can enumerate its keys.
+type KeyedBinding interface {
+	Binding
+
+	// Keys returns the keys of the binding in lexical order.
+	Keys() []string
+}
+
+// ErrorBinding is a Binding that can describe why the contents of a binding entry could not be retrieved.
+type ErrorBinding interface {
+	Binding
+
+	// GetAsBytesE returns the contents of a binding entry in its raw []byte form.  The returned error wraps
+	// ErrInvalidKey, ErrNotFound, ErrNotRegularFile, or the underlying error that prevented the entry from being read.
+	GetAsBytesE(key string) ([]byte, error)
+}
+
 // Get returns contents of a binding entry as a UTF-8 decoded string.  Any whitespace is trimmed.
 func Get(binding Binding, key string) (string, bool) {
 	v, ok := binding.GetAsBytes(key)
@@ -54,6 +96,47 @@ func Get(binding Binding, key string) (string, bool) {
 	return strings.TrimSpace(string(v)), true
 }
 
+// GetAsBytesE returns the contents of a binding entry in its raw []byte form.  If the binding does not implement
+// ErrorBinding, a missing entry is reported as either ErrInvalidKey or ErrNotFound.
+func GetAsBytesE(binding Binding, key string) ([]byte, error) {
+	if e, ok := binding.(ErrorBinding); ok {
+		return e.GetAsBytesE(key)
+	}
+
+	if !internal.IsValidSecretKey(key) {
+		return nil, keyError(binding, key, ErrInvalidKey)
+	}
+
+	v, ok := binding.GetAsBytes(key)
+	if !ok {
+		return nil, keyError(binding, key, ErrNotFound)
+	}
+
+	return v, nil
+}
+
+// GetE returns contents of a binding entry as a UTF-8 decoded string.  Any whitespace is trimmed.  The returned error
+// describes why the entry could not be retrieved.
+func GetE(binding Binding, key string) (string, error) {
+	v, err := GetAsBytesE(binding, key)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(v)), nil
+}
+
+// Keys returns the keys of a binding in lexical order.  If the binding does not implement KeyedBinding, false is
+// returned.
+func Keys(binding Binding) ([]string, bool) {
+	k, ok := binding.(KeyedBinding)
+	if !ok {
+		return nil, false
+	}
+
+	return k.Keys(), true
+}
+
 // GetProvider returns the value of the Provider key.
 func GetProvider(binding Binding) (string, bool) {
 	return Get(binding, Provider)
@@ -75,64 +158,289 @@ type CacheBinding struct {
 	// Delegate is the Binding used to retrieve original values
 	Delegate Binding
 
-	cache map[string][]byte
+	// TTL is the length of time that a value is cached for.  If zero, values are cached until invalidated.
+	TTL time.Duration
+
+	// CacheMisses indicates whether the absence of a key is cached, so that repeated lookups of missing optional keys
+	// do not reach the delegate.
+	CacheMisses bool
+
+	// Revalidate indicates whether the file backing a cached entry is checked for a change in modification time, size,
+	// or identity before the cached value is returned.  Only applies if the delegate is a ConfigTreeBinding or
+	// CNBBinding.
+	Revalidate bool
+
+	// cache holds pointers so that formatting a CacheBinding value, which does not use the redacting methods of
+	// *CacheBinding, prints the addresses of cached entries rather than their values.
+	cache map[string]*cacheEntry
 	mutex sync.Mutex
 }
 
+type cacheEntry struct {
+	value   []byte
+	err     error
+	expires time.Time
+	file    fs.FileInfo
+}
+
 func (c *CacheBinding) GetAsBytes(key string) ([]byte, bool) {
+	v, err := c.GetAsBytesE(key)
+	return v, err == nil
+}
+
+func (c *CacheBinding) GetAsBytesE(key string) ([]byte, error) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
 	if c.cache == nil {
-		c.cache = make(map[string][]byte)
+		c.cache = make(map[string]*cacheEntry)
 	}
 
-	v, ok := c.cache[key]
-	if ok {
-		return v, ok
+	if e, ok := c.cache[key]; ok && c.valid(key, e) {
+		return e.value, e.err
 	}
 
-	v, ok = c.Delegate.GetAsBytes(key)
-	if ok {
-		c.cache[key] = v
+	e := &cacheEntry{file: c.stat(key)}
+	e.value, e.err = c.get(key)
+
+	if e.err == nil || (c.CacheMisses && errors.Is(e.err, ErrNotFound)) {
+		if c.TTL > 0 {
+			e.expires = time.Now().Add(c.TTL)
+		}
+		c.cache[key] = e
+	} else {
+		delete(c.cache, key)
 	}
 
-	return v, ok
+	return e.value, e.err
+}
+
+// get retrieves a value from the delegate.  A delegate that does not implement ErrorBinding is asked for every key, so
+// that it decides which keys are valid.
+func (c *CacheBinding) get(key string) ([]byte, error) {
+	if e, ok := c.Delegate.(ErrorBinding); ok {
+		return e.GetAsBytesE(key)
+	}
+
+	v, ok := c.Delegate.GetAsBytes(key)
+	if !ok {
+		return nil, keyError(c, key, ErrNotFound)
+	}
+
+	return v, nil
+}
+
+// Invalidate removes a key from the cache so that its value is retrieved from the delegate on next use.
+func (c *CacheBinding) Invalidate(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.cache, key)
+}
+
+// InvalidateAll removes all keys from the cache so that their values are retrieved from the delegate on next use.
+func (c *CacheBinding) InvalidateAll() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.cache = nil
+}
+
+func (c *CacheBinding) valid(key string, e *cacheEntry) bool {
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		return false
+	}
+
+	if !c.Revalidate {
+		return true
+	}
+
+	f := c.stat(key)
+	if f == nil || e.file == nil {
+		return f == nil && e.file == nil
+	}
+
+	return os.SameFile(f, e.file) && f.ModTime().Equal(e.file.ModTime()) && f.Size() == e.file.Size()
+}
+
+// stat returns the file backing an entry of the delegate, or nil if revalidation does not apply.
+func (c *CacheBinding) stat(key string) fs.FileInfo {
+	if !c.Revalidate || !internal.IsValidSecretKey(key) {
+		return nil
+	}
+
+	var p string
+	switch d := c.Delegate.(type) {
+	case ConfigTreeBinding:
+		p = filepath.Join(d.Root, key)
+	case CNBBinding:
+		p = d.path(key)
+	default:
+		return nil
+	}
+
+	fi, err := os.Stat(p)
+	if err != nil {
+		return nil
+	}
+
+	return fi
 }
 
 func (c *CacheBinding) GetName() string {
 	return c.Delegate.GetName()
 }
 
+// Keys returns the keys of the delegate.  If the delegate does not implement KeyedBinding, the keys that have been
+// cached are returned.
+func (c *CacheBinding) Keys() []string {
+	if k, ok := Keys(c.Delegate); ok {
+		return k
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	keys := make([]string, 0, len(c.cache))
+	for k, e := range c.cache {
+		if e.err == nil {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
 // ConfigTreeBinding is an implementation of the Binding interface that reads files from a volume mounted Kubernetes
 // secret: https://kubernetes.io/docs/concepts/configuration/secret/#using-secrets.
 type ConfigTreeBinding struct {
 
 	// Root is the filesystem root of the binding.
 	Root string
+
+	// Restrictions are the constraints enforced when reading entries.  The zero value enforces none.
+	Restrictions Restrictions
 }
 
 func (c ConfigTreeBinding) GetAsBytes(key string) ([]byte, bool) {
+	b, err := c.GetAsBytesE(key)
+	return b, err == nil
+}
+
+func (c ConfigTreeBinding) GetAsBytesE(key string) ([]byte, error) {
 	if !internal.IsValidSecretKey(key) {
-		return nil, false
+		return nil, keyError(c, key, ErrInvalidKey)
 	}
 
-	p := filepath.Join(c.Root, key)
+	return readEntry(c, key, c.Root, filepath.Join(c.Root, key), c.Restrictions)
+}
 
-	if fi, err := os.Stat(p); err != nil || !fi.Mode().IsRegular() {
-		return nil, false
+func (c ConfigTreeBinding) GetName() string {
+	return path.Base(c.Root)
+}
+
+// Snapshot reads every entry of the binding into an immutable MapBinding.  If the binding uses the Kubernetes ..data
+// indirection, every entry is read from the same generation so that values from before and after an update are never
+// mixed.  If the generation changes while reading, the read is retried.  Every entry must satisfy the binding's
+// Restrictions.
+func (c ConfigTreeBinding) Snapshot() (MapBinding, error) {
+	_, content, err := snapshotTree(c.Root, c.Restrictions)
+	if err != nil {
+		return MapBinding{}, fmt.Errorf("unable to snapshot binding %s: %w", c.GetName(), err)
 	}
 
-	b, err := os.ReadFile(p)
+	return MapBinding{Name: c.GetName(), Content: content}, nil
+}
+
+// Keys returns the names of the regular files in the binding that are valid secret keys.  Hidden entries such as the
+// Kubernetes ..data symlink and directories are skipped.
+func (c ConfigTreeBinding) Keys() []string {
+	keys, err := listKeys(os.DirFS(c.Root), ".")
 	if err != nil {
-		return nil, false
+		return []string{}
 	}
 
-	return b, true
+	return keys
 }
 
-func (c ConfigTreeBinding) GetName() string {
-	return path.Base(c.Root)
+// Restrictions are opt-in constraints on how a ConfigTreeBinding reads its entries, guarding against projections that
+// link entries to arbitrary files or to files too large to hold in memory.
+type Restrictions struct {
+
+	// Confine rejects entries whose symlinks resolve outside the binding root with ErrOutsideRoot.  The Kubernetes
+	// ..data indirection resolves within the root and is allowed.
+	Confine bool
+
+	// MaxSize rejects entries larger than this many bytes with ErrTooLarge.  Ignored if not positive.
+	MaxSize int64
+
+	// RejectWorldReadable rejects entries whose permissions allow any user to read them with ErrWorldReadable.
+	RejectWorldReadable bool
+}
+
+// read reads the regular file at path, enforcing the restrictions relative to root.
+func (r Restrictions) read(root string, path string) ([]byte, error) {
+	if r.Confine {
+		base, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			return nil, err
+		}
+
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return nil, err
+		}
+
+		rel, err := filepath.Rel(base, resolved)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, ErrOutsideRoot
+		}
+
+		path = resolved
+	}
+
+	return r.readFS(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+}
+
+// readFS reads the regular file at name in fsys, enforcing the restrictions other than Confine.
+func (r Restrictions) readFS(fsys fs.FS, name string) ([]byte, error) {
+	fi, err := fs.Stat(fsys, name)
+	if err != nil {
+		return nil, err
+	} else if !fi.Mode().IsRegular() {
+		return nil, ErrNotRegularFile
+	}
+
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// the file is checked again once open, in case it was replaced after the first check
+	if fi, err = f.Stat(); err != nil {
+		return nil, err
+	} else if !fi.Mode().IsRegular() {
+		return nil, ErrNotRegularFile
+	} else if r.RejectWorldReadable && fi.Mode().Perm()&0004 != 0 {
+		return nil, ErrWorldReadable
+	} else if r.MaxSize > 0 && fi.Size() > r.MaxSize {
+		return nil, ErrTooLarge
+	}
+
+	if r.MaxSize <= 0 {
+		return io.ReadAll(f)
+	}
+
+	b, err := io.ReadAll(io.LimitReader(f, r.MaxSize+1))
+	if err != nil {
+		return nil, err
+	} else if int64(len(b)) > r.MaxSize {
+		return nil, ErrTooLarge
+	}
+
+	return b, nil
 }
 
 // MapBinding is an implementation of the Binding interface that returns values from a map.
@@ -154,6 +462,150 @@ func (m MapBinding) GetAsBytes(key string) ([]byte, bool) {
 	return v, ok
 }
 
+func (m MapBinding) GetAsBytesE(key string) ([]byte, error) {
+	if !internal.IsValidSecretKey(key) {
+		return nil, keyError(m, key, ErrInvalidKey)
+	}
+
+	v, ok := m.Content[key]
+	if !ok {
+		return nil, keyError(m, key, ErrNotFound)
+	}
+
+	return v, nil
+}
+
 func (m MapBinding) GetName() string {
 	return m.Name
 }
+
+// Keys returns the keys of the content that are valid secret keys.
+func (m MapBinding) Keys() []string {
+	keys := make([]string, 0, len(m.Content))
+	for k := range m.Content {
+		if internal.IsValidSecretKey(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// keyError wraps an error with the name of the binding and the key that could not be retrieved.
+func keyError(binding Binding, key string, err error) error {
+	return fmt.Errorf("binding %s key %s: %w", binding.GetName(), key, err)
+}
+
+// readEntry reads the regular file containing the value of a binding entry, enforcing restrictions relative to root.
+func readEntry(binding Binding, key string, root string, path string, restrictions Restrictions) ([]byte, error) {
+	b, err := restrictions.read(root, path)
+	if err != nil {
+		return nil, entryError(binding, key, err)
+	}
+
+	return b, nil
+}
+
+// entryError wraps an error reading the file of a binding entry, reporting a missing file as ErrNotFound.
+func entryError(binding Binding, key string, err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return keyError(binding, key, fmt.Errorf("%w: %w", ErrNotFound, err))
+	}
+
+	return keyError(binding, key, err)
+}
+
+// dataDir is the name of the symlink that Kubernetes atomically swaps to publish a new generation of a projected
+// volume.
+const dataDir = "..data"
+
+// listKeys returns the names of the regular files in a directory of fsys that are valid secret keys.  Entries reserved
+// by the Kubernetes atomic writer (those starting with "..") are skipped.
+func listKeys(fsys fs.FS, dir string) ([]string, error) {
+	children, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, c := range children {
+		n := c.Name()
+		if strings.HasPrefix(n, "..") || !internal.IsValidSecretKey(n) {
+			continue
+		}
+
+		if fi, err := fs.Stat(fsys, path.Join(dir, n)); err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+
+		keys = append(keys, n)
+	}
+
+	return keys, nil
+}
+
+// snapshotAttempts is the number of times a config tree is read before giving up on a consistent generation.
+const snapshotAttempts = 5
+
+// snapshotTree reads every entry of a config tree from a single generation, retrying if the Kubernetes ..data symlink
+// is swapped during the read.
+func snapshotTree(root string, restrictions Restrictions) (string, map[string][]byte, error) {
+	var err error
+
+	for i := 0; i < snapshotAttempts; i++ {
+		var (
+			generation string
+			content    map[string][]byte
+		)
+
+		generation, content, err = readTree(root, restrictions)
+		if err != nil {
+			continue
+		}
+
+		if generation == "" {
+			return generation, content, nil
+		}
+
+		if g, err := os.Readlink(filepath.Join(root, dataDir)); err == nil && g == generation {
+			return generation, content, nil
+		}
+
+		err = fmt.Errorf("generation changed from %s during read", generation)
+	}
+
+	return "", nil, err
+}
+
+// readTree reads every entry of a config tree.  If the tree uses the Kubernetes ..data indirection, the link is
+// resolved once and every entry is read from the generation it points to.  The returned generation is the target of
+// the link, or empty if the tree does not use the indirection.
+func readTree(root string, restrictions Restrictions) (string, map[string][]byte, error) {
+	dir := root
+
+	generation, err := os.Readlink(filepath.Join(root, dataDir))
+	if err != nil {
+		generation = ""
+	} else if filepath.IsAbs(generation) {
+		dir = generation
+	} else {
+		dir = filepath.Join(root, generation)
+	}
+
+	keys, err := listKeys(os.DirFS(dir), ".")
+	if err != nil {
+		return "", nil, err
+	}
+
+	content := make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		b, err := restrictions.read(root, filepath.Join(dir, k))
+		if err != nil {
+			return "", nil, fmt.Errorf("key %s: %w", k, err)
+		}
+		content[k] = b
+	}
+
+	return generation, content, nil
+}
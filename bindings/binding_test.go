@@ -18,10 +18,18 @@ package bindings_test
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/nebhale/client-go/bindings"
+	"github.com/nebhale/client-go/bindings/bindingstest"
 )
 
 func Test_Get_Missing(t *testing.T) {
@@ -50,6 +58,72 @@ func Test_Get_Valid(t *testing.T) {
 	}
 }
 
+func Test_GetE_Missing(t *testing.T) {
+	b := bindings.MapBinding{
+		Name:    "test-name",
+		Content: map[string][]byte{},
+	}
+
+	if _, err := bindings.GetE(b, "test-missing-key"); !errors.Is(err, bindings.ErrNotFound) {
+		t.Errorf("does not identify missing key")
+	}
+}
+
+func Test_GetE_Valid(t *testing.T) {
+	b := bindings.MapBinding{
+		Name: "test-name",
+		Content: map[string][]byte{
+			"test-secret-key": []byte("test-secret-value\n"),
+		},
+	}
+
+	if v, err := bindings.GetE(b, "test-secret-key"); err != nil {
+		t.Errorf("does not identify valid key")
+	} else if v != "test-secret-value" {
+		t.Errorf("returned the wrong value")
+	}
+}
+
+func Test_GetAsBytesE_Fallback_Invalid(t *testing.T) {
+	if _, err := bindings.GetAsBytesE(&stubBinding{}, "test^secret^key"); !errors.Is(err, bindings.ErrInvalidKey) {
+		t.Errorf("does not identify invalid key")
+	}
+}
+
+func Test_GetAsBytesE_Fallback_Missing(t *testing.T) {
+	if _, err := bindings.GetAsBytesE(&stubBinding{}, "test-unknown-key"); !errors.Is(err, bindings.ErrNotFound) {
+		t.Errorf("does not identify missing key")
+	}
+}
+
+func Test_GetAsBytesE_Fallback_Valid(t *testing.T) {
+	if v, err := bindings.GetAsBytesE(&stubBinding{}, "test-secret-key"); err != nil || v == nil {
+		t.Errorf("did not retrieve value")
+	}
+}
+
+func Test_Keys_Keyed(t *testing.T) {
+	b := bindings.MapBinding{
+		Name: "test-name",
+		Content: map[string][]byte{
+			"type":            []byte("test-type-1"),
+			"test-secret-key": []byte("test-secret-value"),
+		},
+	}
+
+	if k, ok := bindings.Keys(b); !ok {
+		t.Errorf("does not identify keyed binding")
+	} else if !reflect.DeepEqual(k, []string{"test-secret-key", "type"}) {
+		t.Errorf("returned the wrong value")
+	}
+}
+
+func Test_Keys_Unkeyed(t *testing.T) {
+	if _, ok := bindings.Keys(&stubBinding{}); ok {
+		t.Errorf("does not identify unkeyed binding")
+	}
+}
+
 func Test_GetProvider_Missing(t *testing.T) {
 	b := bindings.MapBinding{
 		Name:    "test-name",
@@ -133,6 +207,139 @@ func Test_CacheBinding_Valid(t *testing.T) {
 	}
 }
 
+func Test_CacheBinding_GetAsBytesE_Missing(t *testing.T) {
+	s := &stubBinding{}
+	b := bindings.CacheBinding{Delegate: s}
+
+	if _, err := b.GetAsBytesE("test-unknown-key"); !errors.Is(err, bindings.ErrNotFound) {
+		t.Errorf("does not identify missing key")
+	}
+	if _, err := b.GetAsBytesE("test-unknown-key"); !errors.Is(err, bindings.ErrNotFound) {
+		t.Errorf("does not identify missing key")
+	}
+	if s.getAsBytesCount != 2 {
+		t.Errorf("does not call delegate enough")
+	}
+}
+
+func Test_CacheBinding_GetAsBytesE_Valid(t *testing.T) {
+	s := &stubBinding{}
+	b := bindings.CacheBinding{Delegate: s}
+
+	if v, err := b.GetAsBytesE("test-secret-key"); err != nil || v == nil {
+		t.Errorf("did not retrieve value")
+	}
+	if v, err := b.GetAsBytesE("test-secret-key"); err != nil || v == nil {
+		t.Errorf("did not retrieve value")
+	}
+	if s.getAsBytesCount != 1 {
+		t.Errorf("did not call delegate correctly")
+	}
+}
+
+func Test_CacheBinding_NonValidatingDelegate(t *testing.T) {
+	s := &stubBinding{}
+	b := bindings.CacheBinding{Delegate: s}
+
+	if v, ok := b.GetAsBytes("test/secret/key"); !ok || v == nil {
+		t.Errorf("did not retrieve value")
+	}
+	if s.getAsBytesCount != 1 {
+		t.Errorf("did not call delegate")
+	}
+}
+
+func Test_CacheBinding_TTL(t *testing.T) {
+	s := &stubBinding{}
+	b := bindings.CacheBinding{Delegate: s, TTL: time.Millisecond}
+
+	b.GetAsBytes("test-secret-key")
+	time.Sleep(5 * time.Millisecond)
+	b.GetAsBytes("test-secret-key")
+
+	if s.getAsBytesCount != 2 {
+		t.Errorf("did not expire value")
+	}
+}
+
+func Test_CacheBinding_CacheMisses(t *testing.T) {
+	s := &stubBinding{}
+	b := bindings.CacheBinding{Delegate: s, CacheMisses: true}
+
+	if _, ok := b.GetAsBytes("test-unknown-key"); ok {
+		t.Errorf("does not identify missing key")
+	}
+	if _, err := b.GetAsBytesE("test-unknown-key"); !errors.Is(err, bindings.ErrNotFound) {
+		t.Errorf("does not identify missing key")
+	}
+	if s.getAsBytesCount != 1 {
+		t.Errorf("did not cache miss")
+	}
+	if len(b.Keys()) != 0 {
+		t.Errorf("returned missing key")
+	}
+}
+
+func Test_CacheBinding_Invalidate(t *testing.T) {
+	s := &stubBinding{}
+	b := bindings.CacheBinding{Delegate: s}
+
+	b.GetAsBytes("test-secret-key")
+	b.Invalidate("test-secret-key")
+	b.GetAsBytes("test-secret-key")
+
+	if s.getAsBytesCount != 2 {
+		t.Errorf("did not invalidate value")
+	}
+}
+
+func Test_CacheBinding_InvalidateAll(t *testing.T) {
+	s := &stubBinding{}
+	b := bindings.CacheBinding{Delegate: s}
+
+	b.GetAsBytes("test-secret-key")
+	b.InvalidateAll()
+	b.GetAsBytes("test-secret-key")
+
+	if s.getAsBytesCount != 2 {
+		t.Errorf("did not invalidate value")
+	}
+}
+
+func Test_CacheBinding_Revalidate(t *testing.T) {
+	r := bindingstest.NewRoot(t).Kubernetes()
+	d := r.Binding("test-name").Entry("test-secret-key", "test-secret-value")
+	b := bindings.CacheBinding{Delegate: bindings.ConfigTreeBinding{Root: d.Path()}, Revalidate: true, CacheMisses: true}
+
+	if v, _ := bindings.Get(&b, "test-secret-key"); v != "test-secret-value" {
+		t.Errorf("returned the wrong value")
+	}
+	if _, ok := b.GetAsBytes("test-optional-key"); ok {
+		t.Errorf("does not identify missing key")
+	}
+
+	d.Entry("test-secret-key", "test-secret-value-2").Entry("test-optional-key", "test-optional-value")
+
+	if v, _ := bindings.Get(&b, "test-secret-key"); v != "test-secret-value-2" {
+		t.Errorf("did not revalidate value")
+	}
+	if _, ok := b.GetAsBytes("test-optional-key"); !ok {
+		t.Errorf("did not revalidate miss")
+	}
+}
+
+func Test_CacheBinding_NoRevalidate(t *testing.T) {
+	d := bindingstest.NewRoot(t).Binding("test-name").Entry("test-secret-key", "test-secret-value")
+	b := bindings.CacheBinding{Delegate: bindings.ConfigTreeBinding{Root: d.Path()}}
+
+	bindings.Get(&b, "test-secret-key")
+	d.Entry("test-secret-key", "test-secret-value-2")
+
+	if v, _ := bindings.Get(&b, "test-secret-key"); v != "test-secret-value" {
+		t.Errorf("did not cache value")
+	}
+}
+
 func Test_CacheBinding_GetName(t *testing.T) {
 	s := &stubBinding{}
 	b := bindings.CacheBinding{Delegate: s}
@@ -148,6 +355,33 @@ func Test_CacheBinding_GetName(t *testing.T) {
 	}
 }
 
+func Test_CacheBinding_Keys_Keyed(t *testing.T) {
+	b := bindings.CacheBinding{Delegate: bindings.MapBinding{
+		Name: "test-name",
+		Content: map[string][]byte{
+			"test-secret-key": []byte("test-secret-value"),
+		},
+	}}
+
+	if !reflect.DeepEqual(b.Keys(), []string{"test-secret-key"}) {
+		t.Errorf("returned the wrong value")
+	}
+}
+
+func Test_CacheBinding_Keys_Unkeyed(t *testing.T) {
+	s := &stubBinding{}
+	b := bindings.CacheBinding{Delegate: s}
+
+	if len(b.Keys()) != 0 {
+		t.Errorf("returned the wrong value")
+	}
+
+	b.GetAsBytes("test-secret-key")
+	if !reflect.DeepEqual(b.Keys(), []string{"test-secret-key"}) {
+		t.Errorf("returned the wrong value")
+	}
+}
+
 func Test_ConfigTreeBinding__Missing(t *testing.T) {
 	b := bindings.ConfigTreeBinding{
 		Root: filepath.Join("testdata", "test-k8s"),
@@ -200,6 +434,294 @@ func Test_ConfigTreeBinding_GetName(t *testing.T) {
 	}
 }
 
+func Test_ConfigTreeBinding_GetAsBytesE_Missing(t *testing.T) {
+	b := bindings.ConfigTreeBinding{
+		Root: filepath.Join("testdata", "test-k8s"),
+	}
+
+	_, err := b.GetAsBytesE("test-missing-key")
+	if !errors.Is(err, bindings.ErrNotFound) {
+		t.Errorf("does not identify missing key")
+	}
+
+	var e *fs.PathError
+	if !errors.As(err, &e) {
+		t.Errorf("does not wrap path error")
+	}
+}
+
+func Test_ConfigTreeBinding_GetAsBytesE_Directory(t *testing.T) {
+	b := bindings.ConfigTreeBinding{
+		Root: filepath.Join("testdata", "test-k8s"),
+	}
+
+	if _, err := b.GetAsBytesE(".hidden-data"); !errors.Is(err, bindings.ErrNotRegularFile) {
+		t.Errorf("does not identify directory")
+	}
+}
+
+func Test_ConfigTreeBinding_GetAsBytesE_Invalid(t *testing.T) {
+	b := bindings.ConfigTreeBinding{
+		Root: filepath.Join("testdata", "test-k8s"),
+	}
+
+	if _, err := b.GetAsBytesE("test^secret^key"); !errors.Is(err, bindings.ErrInvalidKey) {
+		t.Errorf("does not identify invalid key")
+	}
+}
+
+func Test_ConfigTreeBinding_GetAsBytesE_Valid(t *testing.T) {
+	b := bindings.ConfigTreeBinding{
+		Root: filepath.Join("testdata", "test-k8s"),
+	}
+
+	if v, err := b.GetAsBytesE("test-secret-key"); err != nil {
+		t.Errorf("does not identify valid key")
+	} else if !bytes.Equal([]byte("test-secret-value\n"), v) {
+		t.Errorf("returned the wrong value")
+	}
+}
+
+func Test_ConfigTreeBinding_Snapshot(t *testing.T) {
+	b := bindings.ConfigTreeBinding{
+		Root: filepath.Join("testdata", "test-k8s"),
+	}
+
+	s, err := b.Snapshot()
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	if s.Name != "test-k8s" {
+		t.Errorf("returned the wrong name")
+	}
+	if !reflect.DeepEqual(s.Keys(), []string{"provider", "test-secret-key", "type"}) {
+		t.Errorf("returned the wrong keys: %v", s.Keys())
+	}
+	if !bytes.Equal(s.Content["test-secret-key"], []byte("test-secret-value\n")) {
+		t.Errorf("returned the wrong value")
+	}
+}
+
+func Test_ConfigTreeBinding_Snapshot_Missing(t *testing.T) {
+	b := bindings.ConfigTreeBinding{
+		Root: filepath.Join("testdata", "missing"),
+	}
+
+	if _, err := b.Snapshot(); err == nil {
+		t.Errorf("does not identify missing binding")
+	}
+}
+
+func Test_ConfigTreeBinding_Snapshot_Consistent(t *testing.T) {
+	d := bindingstest.NewRoot(t).Kubernetes().Binding("test-name").Entries(map[string]string{
+		"username": "test-username-0",
+		"password": "test-password-0",
+	})
+	b := bindings.ConfigTreeBinding{Root: d.Path()}
+
+	done := make(chan struct{})
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			s, err := b.Snapshot()
+			if err != nil {
+				continue
+			}
+
+			u := strings.TrimPrefix(string(s.Content["username"]), "test-username-")
+			p := strings.TrimPrefix(string(s.Content["password"]), "test-password-")
+			if u != p {
+				errs <- fmt.Errorf("mixed generations: username %s, password %s", u, p)
+				return
+			}
+		}
+	}()
+
+	for i := 1; i <= 100; i++ {
+		d.Entries(map[string]string{
+			"username": fmt.Sprintf("test-username-%d", i),
+			"password": fmt.Sprintf("test-password-%d", i),
+		})
+	}
+	close(done)
+
+	if err := <-errs; err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_ConfigTreeBinding_Keys(t *testing.T) {
+	b := bindings.ConfigTreeBinding{
+		Root: filepath.Join("testdata", "test-k8s"),
+	}
+
+	if !reflect.DeepEqual(b.Keys(), []string{"provider", "test-secret-key", "type"}) {
+		t.Errorf("returned the wrong value")
+	}
+}
+
+func Test_ConfigTreeBinding_Keys_Missing(t *testing.T) {
+	b := bindings.ConfigTreeBinding{
+		Root: filepath.Join("testdata", "missing"),
+	}
+
+	if len(b.Keys()) != 0 {
+		t.Errorf("returned the wrong value")
+	}
+}
+
+func Test_ConfigTreeBinding_Restrictions_Confine(t *testing.T) {
+	outside := filepath.Join(t.TempDir(), "outside")
+	if err := os.WriteFile(outside, []byte("test-outside-value"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	d := bindingstest.NewRoot(t).Binding("test-name").Entry("test-secret-key", "test-secret-value")
+	if err := os.Symlink(outside, filepath.Join(d.Path(), "test-escape-key")); err != nil {
+		t.Fatal(err)
+	}
+
+	b := bindings.ConfigTreeBinding{Root: d.Path()}
+	if _, err := b.GetAsBytesE("test-escape-key"); err != nil {
+		t.Errorf("restricted unconfined binding: %v", err)
+	}
+
+	b.Restrictions.Confine = true
+	if _, err := b.GetAsBytesE("test-escape-key"); !errors.Is(err, bindings.ErrOutsideRoot) {
+		t.Errorf("does not identify escaping symlink: %v", err)
+	}
+	if v, err := b.GetAsBytesE("test-secret-key"); err != nil || string(v) != "test-secret-value" {
+		t.Errorf("restricted entry within root: %v", err)
+	}
+	if _, err := b.GetAsBytesE("test-missing-key"); !errors.Is(err, bindings.ErrNotFound) {
+		t.Errorf("does not identify missing key: %v", err)
+	}
+	if _, err := b.Snapshot(); !errors.Is(err, bindings.ErrOutsideRoot) {
+		t.Errorf("does not identify escaping symlink in snapshot: %v", err)
+	}
+}
+
+func Test_ConfigTreeBinding_Restrictions_Confine_Kubernetes(t *testing.T) {
+	d := bindingstest.NewRoot(t).Kubernetes().Binding("test-name").Entry("test-secret-key", "test-secret-value")
+
+	b := bindings.ConfigTreeBinding{Root: d.Path(), Restrictions: bindings.Restrictions{Confine: true}}
+	if v, err := b.GetAsBytesE("test-secret-key"); err != nil || string(v) != "test-secret-value" {
+		t.Errorf("does not follow ..data indirection: %v", err)
+	}
+	if s, err := b.Snapshot(); err != nil || string(s.Content["test-secret-key"]) != "test-secret-value" {
+		t.Errorf("does not follow ..data indirection in snapshot: %v", err)
+	}
+}
+
+func Test_ConfigTreeBinding_Restrictions_Confine_DataDir(t *testing.T) {
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "test-secret-key"), []byte("test-secret-value"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	d := bindingstest.NewRoot(t).Binding("test-name")
+	if err := os.Symlink(outside, filepath.Join(d.Path(), "..data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("..data", "test-secret-key"), filepath.Join(d.Path(), "test-secret-key")); err != nil {
+		t.Fatal(err)
+	}
+
+	b := bindings.ConfigTreeBinding{Root: d.Path(), Restrictions: bindings.Restrictions{Confine: true}}
+	if _, err := b.GetAsBytesE("test-secret-key"); !errors.Is(err, bindings.ErrOutsideRoot) {
+		t.Errorf("does not identify escaping ..data symlink: %v", err)
+	}
+}
+
+func Test_ConfigTreeBinding_Restrictions_MaxSize(t *testing.T) {
+	d := bindingstest.NewRoot(t).Kubernetes().Binding("test-name").Entries(map[string]string{
+		"test-small-key": "1234",
+		"test-large-key": "12345",
+	})
+
+	b := bindings.ConfigTreeBinding{Root: d.Path(), Restrictions: bindings.Restrictions{MaxSize: 4}}
+	if v, err := b.GetAsBytesE("test-small-key"); err != nil || string(v) != "1234" {
+		t.Errorf("restricted entry within maximum size: %v", err)
+	}
+	if _, err := b.GetAsBytesE("test-large-key"); !errors.Is(err, bindings.ErrTooLarge) {
+		t.Errorf("does not identify oversize entry: %v", err)
+	}
+	if _, err := b.Snapshot(); !errors.Is(err, bindings.ErrTooLarge) {
+		t.Errorf("does not identify oversize entry in snapshot: %v", err)
+	}
+}
+
+func Test_ConfigTreeBinding_Restrictions_RejectWorldReadable(t *testing.T) {
+	d := bindingstest.NewRoot(t).Binding("test-name").Entries(map[string]string{
+		"test-public-key":  "test-public-value",
+		"test-private-key": "test-private-value",
+	})
+	if err := os.Chmod(filepath.Join(d.Path(), "test-public-key"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(d.Path(), "test-private-key"), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	b := bindings.ConfigTreeBinding{Root: d.Path(), Restrictions: bindings.Restrictions{RejectWorldReadable: true}}
+	if _, err := b.GetAsBytesE("test-private-key"); err != nil {
+		t.Errorf("restricted private entry: %v", err)
+	}
+	if _, err := b.GetAsBytesE("test-public-key"); !errors.Is(err, bindings.ErrWorldReadable) {
+		t.Errorf("does not identify world-readable entry: %v", err)
+	}
+	if _, ok := b.GetAsBytes("test-public-key"); ok {
+		t.Errorf("returned world-readable entry")
+	}
+}
+
+func Test_MapBinding_GetAsBytesE_Missing(t *testing.T) {
+	b := bindings.MapBinding{
+		Name:    "test-name",
+		Content: map[string][]byte{},
+	}
+
+	if _, err := b.GetAsBytesE("test-missing-key"); !errors.Is(err, bindings.ErrNotFound) {
+		t.Errorf("does not identify missing key")
+	}
+}
+
+func Test_MapBinding_GetAsBytesE_Invalid(t *testing.T) {
+	b := bindings.MapBinding{
+		Name:    "test-name",
+		Content: map[string][]byte{},
+	}
+
+	if _, err := b.GetAsBytesE("test^invalid^key"); !errors.Is(err, bindings.ErrInvalidKey) {
+		t.Errorf("does not identify invalid key")
+	}
+}
+
+func Test_MapBinding_Keys(t *testing.T) {
+	b := bindings.MapBinding{
+		Name: "test-name",
+		Content: map[string][]byte{
+			"type":             []byte("test-type-1"),
+			"test-secret-key":  []byte("test-secret-value"),
+			"test^invalid^key": []byte("test-secret-value"),
+		},
+	}
+
+	if !reflect.DeepEqual(b.Keys(), []string{"test-secret-key", "type"}) {
+		t.Errorf("returned the wrong value")
+	}
+}
+
 func Test_MapBinding_Missing(t *testing.T) {
 	b := bindings.MapBinding{
 		Name: "test-name",
@@ -258,7 +780,8 @@ type stubBinding struct {
 func (s *stubBinding) GetAsBytes(key string) ([]byte, bool) {
 	s.getAsBytesCount++
 
-	if key == "test-secret-key" {
+	// keys are not validated, as a third-party binding may accept keys that are not valid secret keys
+	if key == "test-secret-key" || key == "test/secret/key" {
 		return []byte{}, true
 	}
 
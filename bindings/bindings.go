@@ -17,6 +17,7 @@
 package bindings
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -37,16 +38,38 @@ func Cached(bindings []Binding) []Binding {
 	return w
 }
 
+// Restricted applies Restrictions to each ConfigTreeBinding.  Other Bindings are returned unchanged.
+func Restricted(bindings []Binding, restrictions Restrictions) []Binding {
+	var w []Binding
+
+	for _, b := range bindings {
+		if c, ok := b.(ConfigTreeBinding); ok {
+			c.Restrictions = restrictions
+			b = c
+		}
+		w = append(w, b)
+	}
+
+	return w
+}
+
 // From creates a collection Bindings from the specified path.  If the directory does not exist an, empty collection is
 // returned.
 func From(root string) []Binding {
-	if fi, err := os.Stat(root); err != nil || !fi.IsDir() {
+	bindings, err := FromE(root)
+	if err != nil {
 		return []Binding{}
 	}
 
+	return bindings
+}
+
+// FromE creates a collection of Bindings from the specified path.  If the directory cannot be read, the returned error
+// wraps the underlying *fs.PathError.
+func FromE(root string) ([]Binding, error) {
 	children, err := os.ReadDir(root)
 	if err != nil {
-		return []Binding{}
+		return nil, fmt.Errorf("unable to read bindings root: %w", err)
 	}
 
 	var bindings []Binding
@@ -58,7 +81,7 @@ func From(root string) []Binding {
 		bindings = append(bindings, ConfigTreeBinding{Root: filepath.Join(root, c.Name())})
 	}
 
-	return bindings
+	return bindings, nil
 }
 
 // FromServiceBindingRoot creates Bindings using the $SERVICE_BINDING_ROOT environment variable to determine the file
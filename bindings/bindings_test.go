@@ -17,7 +17,8 @@
 package bindings_test
 
 import (
-	"os"
+	"errors"
+	"io/fs"
 	"reflect"
 	"testing"
 
@@ -37,6 +38,21 @@ func Test_Cached(t *testing.T) {
 	}
 }
 
+func Test_Restricted(t *testing.T) {
+	r := bindings.Restrictions{Confine: true, MaxSize: 1024}
+	b := bindings.Restricted([]bindings.Binding{
+		bindings.ConfigTreeBinding{Root: "test-root"},
+		bindings.MapBinding{Name: "test-name"},
+	}, r)
+
+	if c, ok := b[0].(bindings.ConfigTreeBinding); !ok || c.Restrictions != r || c.Root != "test-root" {
+		t.Errorf("did not restrict ConfigTreeBinding")
+	}
+	if _, ok := b[1].(bindings.MapBinding); !ok {
+		t.Errorf("did not return other binding unchanged")
+	}
+}
+
 func Test_From_Missing(t *testing.T) {
 	if !reflect.DeepEqual(bindings.From("missing"), []bindings.Binding{}) {
 		t.Errorf("did not create an empty Bindings")
@@ -55,6 +71,32 @@ func Test_From_Valid(t *testing.T) {
 	}
 }
 
+func Test_FromE_Missing(t *testing.T) {
+	_, err := bindings.FromE("missing")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("does not identify missing directory")
+	}
+
+	var e *fs.PathError
+	if !errors.As(err, &e) {
+		t.Errorf("does not wrap path error")
+	}
+}
+
+func Test_FromE_File(t *testing.T) {
+	if _, err := bindings.FromE("testdata/additional-file"); err == nil {
+		t.Errorf("does not identify file")
+	}
+}
+
+func Test_FromE_Valid(t *testing.T) {
+	if b, err := bindings.FromE("testdata"); err != nil {
+		t.Errorf("returned an error: %v", err)
+	} else if len(b) != 3 {
+		t.Errorf("did not create proper number of bindings")
+	}
+}
+
 func Test_FromServiceBindingRoot_Unset(t *testing.T) {
 	if !reflect.DeepEqual(bindings.FromServiceBindingRoot(), []bindings.Binding{}) {
 		t.Errorf("did not create an empty Bindings")
@@ -62,21 +104,7 @@ func Test_FromServiceBindingRoot_Unset(t *testing.T) {
 }
 
 func Test_FromServiceBindingRoot_Set(t *testing.T) {
-	old, ok := os.LookupEnv("SERVICE_BINDING_ROOT")
-	if err := os.Setenv("SERVICE_BINDING_ROOT", "testdata"); err != nil {
-		t.Errorf("unable to set SERVICE_BINDING_ROOT")
-	}
-	defer func() {
-		if ok {
-			if err := os.Setenv("SERVICE_BINDING_ROOT", old); err != nil {
-				t.Errorf("unable to set SERVICE_BINDING_ROOT")
-			}
-		} else {
-			if err := os.Unsetenv("SERVICE_BINDING_ROOT"); err != nil {
-				t.Errorf("unable to unset SERVICE_BINDING_ROOT")
-			}
-		}
-	}()
+	t.Setenv("SERVICE_BINDING_ROOT", "testdata")
 
 	if len(bindings.FromServiceBindingRoot()) != 3 {
 		t.Errorf("did not create proper number of bindings")
@@ -0,0 +1,279 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindingstest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/nebhale/client-go/bindings"
+)
+
+// Root is a bindings file system root in a temporary directory that is removed when the test completes.
+type Root struct {
+	t          testing.TB
+	path       string
+	kubernetes bool
+	bindings   map[string]*Binding
+}
+
+// NewRoot creates an empty bindings root.
+func NewRoot(t testing.TB) *Root {
+	t.Helper()
+
+	return &Root{t: t, path: t.TempDir(), bindings: map[string]*Binding{}}
+}
+
+// Kubernetes configures bindings subsequently created in the root to use the Kubernetes projected volume layout, where
+// each entry is a symlink through a ..data symlink to a timestamped generation directory.  Each change to a binding
+// creates a new generation and atomically swaps the ..data symlink.
+func (r *Root) Kubernetes() *Root {
+	r.kubernetes = true
+	return r
+}
+
+// Path returns the path of the root.
+func (r *Root) Path() string {
+	return r.path
+}
+
+// Setenv sets $SERVICE_BINDING_ROOT to the path of the root for the duration of the test.
+func (r *Root) Setenv() *Root {
+	r.t.Helper()
+
+	r.t.Setenv(bindings.ServiceBindingRoot, r.path)
+	return r
+}
+
+// Binding returns the binding with a given name, creating it if it does not exist.
+func (r *Root) Binding(name string) *Binding {
+	r.t.Helper()
+
+	if b, ok := r.bindings[name]; ok {
+		return b
+	}
+
+	b := &Binding{root: r, path: filepath.Join(r.path, name), kubernetes: r.kubernetes, entries: map[string][]byte{}}
+	if err := os.MkdirAll(b.path, 0755); err != nil {
+		r.t.Fatalf("unable to create binding %s: %v", name, err)
+	}
+
+	r.bindings[name] = b
+	return b
+}
+
+// Remove removes the binding with a given name.
+func (r *Root) Remove(name string) *Root {
+	r.t.Helper()
+
+	if err := os.RemoveAll(filepath.Join(r.path, name)); err != nil {
+		r.t.Fatalf("unable to remove binding %s: %v", name, err)
+	}
+
+	delete(r.bindings, name)
+	return r
+}
+
+// Binding is a binding within a Root.  Every change is written to the file system immediately.
+type Binding struct {
+	root       *Root
+	path       string
+	kubernetes bool
+	entries    map[string][]byte
+	generation int
+}
+
+// Path returns the path of the binding.
+func (b *Binding) Path() string {
+	return b.path
+}
+
+// Type sets the type of the binding.
+func (b *Binding) Type(value string) *Binding {
+	b.root.t.Helper()
+	return b.Entry(bindings.Type, value)
+}
+
+// Provider sets the provider of the binding.
+func (b *Binding) Provider(value string) *Binding {
+	b.root.t.Helper()
+	return b.Entry(bindings.Provider, value)
+}
+
+// Entry sets the value of an entry in the binding.
+func (b *Binding) Entry(key string, value string) *Binding {
+	b.root.t.Helper()
+	return b.EntryBytes(key, []byte(value))
+}
+
+// EntryBytes sets the raw value of an entry in the binding.
+func (b *Binding) EntryBytes(key string, value []byte) *Binding {
+	b.root.t.Helper()
+
+	b.entries[key] = value
+	if !b.kubernetes {
+		atomicWrite(b.root.t, b.path, key, value)
+		return b
+	}
+
+	b.swap()
+	return b
+}
+
+// Entries sets the values of several entries in the binding at once.  With the Kubernetes layout, the entries are
+// published in a single generation.
+func (b *Binding) Entries(entries map[string]string) *Binding {
+	b.root.t.Helper()
+
+	for k, v := range entries {
+		b.entries[k] = []byte(v)
+		if !b.kubernetes {
+			atomicWrite(b.root.t, b.path, k, []byte(v))
+		}
+	}
+
+	if b.kubernetes {
+		b.swap()
+	}
+	return b
+}
+
+// Remove removes an entry from the binding.
+func (b *Binding) Remove(key string) *Binding {
+	b.root.t.Helper()
+
+	delete(b.entries, key)
+	if !b.kubernetes {
+		if err := os.Remove(filepath.Join(b.path, key)); err != nil {
+			b.root.t.Fatalf("unable to remove %s: %v", key, err)
+		}
+		return b
+	}
+
+	b.swap()
+	if err := os.Remove(filepath.Join(b.path, key)); err != nil {
+		b.root.t.Fatalf("unable to remove %s: %v", key, err)
+	}
+	return b
+}
+
+// swap writes every entry to a new generation directory and atomically points ..data at it.
+func (b *Binding) swap() {
+	b.root.t.Helper()
+
+	b.generation++
+	g := fmt.Sprintf("..%s.%d", time.Now().Format("2006_01_02_15_04_05"), b.generation)
+
+	if err := os.Mkdir(filepath.Join(b.path, g), 0755); err != nil {
+		b.root.t.Fatalf("unable to create generation %s: %v", g, err)
+	}
+
+	keys := make([]string, 0, len(b.entries))
+	for k := range b.entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if err := os.WriteFile(filepath.Join(b.path, g, k), b.entries[k], 0644); err != nil {
+			b.root.t.Fatalf("unable to write %s: %v", k, err)
+		}
+	}
+
+	previous, _ := os.Readlink(filepath.Join(b.path, "..data"))
+
+	if err := os.Symlink(g, filepath.Join(b.path, "..data_tmp")); err != nil {
+		b.root.t.Fatalf("unable to create ..data: %v", err)
+	}
+	if err := os.Rename(filepath.Join(b.path, "..data_tmp"), filepath.Join(b.path, "..data")); err != nil {
+		b.root.t.Fatalf("unable to swap ..data: %v", err)
+	}
+
+	for _, k := range keys {
+		if _, err := os.Lstat(filepath.Join(b.path, k)); err == nil {
+			continue
+		}
+		if err := os.Symlink(filepath.Join("..data", k), filepath.Join(b.path, k)); err != nil {
+			b.root.t.Fatalf("unable to link %s: %v", k, err)
+		}
+	}
+
+	if previous != "" {
+		if err := os.RemoveAll(filepath.Join(b.path, previous)); err != nil {
+			b.root.t.Fatalf("unable to remove generation %s: %v", previous, err)
+		}
+	}
+}
+
+// Certificate creates a self-signed PEM encoded certificate for the host test-host, and its PEM encoded private key.
+// The certificate can be used as a certificate authority, server certificate, or client certificate.
+func Certificate(t testing.TB) ([]byte, []byte) {
+	t.Helper()
+
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "test-host"},
+		DNSNames:              []string{"test-host"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	c, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &k.PublicKey, k)
+	if err != nil {
+		t.Fatalf("unable to create certificate: %v", err)
+	}
+
+	p, err := x509.MarshalPKCS8PrivateKey(k)
+	if err != nil {
+		t.Fatalf("unable to marshal key: %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: p})
+}
+
+func atomicWrite(t testing.TB, dir string, key string, value []byte) {
+	t.Helper()
+
+	tmp := filepath.Join(dir, "..tmp")
+	if err := os.WriteFile(tmp, value, 0644); err != nil {
+		t.Fatalf("unable to write %s: %v", key, err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, key)); err != nil {
+		t.Fatalf("unable to write %s: %v", key, err)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindingstest_test
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nebhale/client-go/bindings"
+	"github.com/nebhale/client-go/bindings/bindingstest"
+)
+
+func Test_Root_Binding(t *testing.T) {
+	r := bindingstest.NewRoot(t)
+	r.Binding("test-name-1").Type("test-type-1").Provider("test-provider-1").Entry("test-secret-key", "test-secret-value")
+
+	b, ok := bindings.Find(bindings.From(r.Path()), "test-name-1")
+	if !ok {
+		t.Fatalf("did not create binding")
+	}
+
+	if v, err := bindings.GetType(b); err != nil || v != "test-type-1" {
+		t.Errorf("did not set type")
+	}
+	if v, ok := bindings.GetProvider(b); !ok || v != "test-provider-1" {
+		t.Errorf("did not set provider")
+	}
+	if v, ok := bindings.Get(b, "test-secret-key"); !ok || v != "test-secret-value" {
+		t.Errorf("did not set entry")
+	}
+}
+
+func Test_Root_Binding_Existing(t *testing.T) {
+	r := bindingstest.NewRoot(t)
+
+	if r.Binding("test-name-1") != r.Binding("test-name-1") {
+		t.Errorf("did not return existing binding")
+	}
+}
+
+func Test_Root_Remove(t *testing.T) {
+	r := bindingstest.NewRoot(t)
+	r.Binding("test-name-1").Type("test-type-1")
+	r.Remove("test-name-1")
+
+	if len(bindings.From(r.Path())) != 0 {
+		t.Errorf("did not remove binding")
+	}
+}
+
+func Test_Root_Setenv(t *testing.T) {
+	r := bindingstest.NewRoot(t).Setenv()
+	r.Binding("test-name-1").Type("test-type-1")
+
+	if len(bindings.FromServiceBindingRoot()) != 1 {
+		t.Errorf("did not set SERVICE_BINDING_ROOT")
+	}
+}
+
+func Test_Binding_Mutate(t *testing.T) {
+	b := bindingstest.NewRoot(t).Binding("test-name-1").Entry("test-secret-key", "test-secret-value")
+	c := bindings.ConfigTreeBinding{Root: b.Path()}
+
+	b.Entry("test-secret-key", "test-secret-value-2")
+	if v, _ := bindings.Get(c, "test-secret-key"); v != "test-secret-value-2" {
+		t.Errorf("did not update entry")
+	}
+
+	b.Remove("test-secret-key")
+	if _, ok := c.GetAsBytes("test-secret-key"); ok {
+		t.Errorf("did not remove entry")
+	}
+}
+
+func Test_Binding_Entries(t *testing.T) {
+	b := bindingstest.NewRoot(t).Kubernetes().Binding("test-name-1").Entries(map[string]string{
+		"username": "test-username",
+		"password": "test-password",
+	})
+	c := bindings.ConfigTreeBinding{Root: b.Path()}
+
+	if v, _ := bindings.Get(c, "username"); v != "test-username" {
+		t.Errorf("did not set entry")
+	}
+	if v, _ := bindings.Get(c, "password"); v != "test-password" {
+		t.Errorf("did not set entry")
+	}
+}
+
+func Test_Binding_Kubernetes(t *testing.T) {
+	b := bindingstest.NewRoot(t).Kubernetes().Binding("test-name-1").
+		Type("test-type-1").
+		Entry("test-secret-key", "test-secret-value")
+	c := bindings.ConfigTreeBinding{Root: b.Path()}
+
+	first, err := os.Readlink(filepath.Join(b.Path(), "..data"))
+	if err != nil {
+		t.Fatalf("did not create ..data: %v", err)
+	}
+	if l, err := os.Readlink(filepath.Join(b.Path(), "test-secret-key")); err != nil || l != filepath.Join("..data", "test-secret-key") {
+		t.Errorf("did not link entry through ..data")
+	}
+
+	b.Entry("test-secret-key", "test-secret-value-2")
+
+	second, err := os.Readlink(filepath.Join(b.Path(), "..data"))
+	if err != nil || second == first {
+		t.Errorf("did not swap ..data")
+	}
+	if _, err := os.Stat(filepath.Join(b.Path(), first)); !os.IsNotExist(err) {
+		t.Errorf("did not remove previous generation")
+	}
+	if v, _ := bindings.Get(c, "test-secret-key"); v != "test-secret-value-2" {
+		t.Errorf("did not update entry")
+	}
+
+	b.Remove("test-secret-key")
+	if _, ok := c.GetAsBytes("test-secret-key"); ok {
+		t.Errorf("did not remove entry")
+	}
+	if v, _ := bindings.Get(c, "type"); v != "test-type-1" {
+		t.Errorf("did not retain entry")
+	}
+}
+
+func Test_Certificate(t *testing.T) {
+	c, k := bindingstest.Certificate(t)
+
+	if _, err := tls.X509KeyPair(c, k); err != nil {
+		t.Errorf("did not create key pair: %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/nebhale/client-go/internal"
+)
+
+// CNBBindings is the name of the environment variable read to determine the legacy Cloud Native Buildpacks bindings
+// file system root.
+const CNBBindings = "CNB_BINDINGS"
+
+// CNBBinding is an implementation of the Binding interface that reads files from the legacy Cloud Native Buildpacks
+// binding layout.  The Type is read from metadata/kind, the Provider from metadata/provider, and all other entries
+// from the secret directory.
+type CNBBinding struct {
+
+	// Root is the filesystem root of the binding.
+	Root string
+}
+
+func (c CNBBinding) GetAsBytes(key string) ([]byte, bool) {
+	b, err := c.GetAsBytesE(key)
+	return b, err == nil
+}
+
+func (c CNBBinding) GetAsBytesE(key string) ([]byte, error) {
+	if !internal.IsValidSecretKey(key) {
+		return nil, keyError(c, key, ErrInvalidKey)
+	}
+
+	return readEntry(c, key, c.Root, c.path(key), Restrictions{})
+}
+
+func (c CNBBinding) GetName() string {
+	return path.Base(c.Root)
+}
+
+// Keys returns the names of the regular files in the secret directory that are valid secret keys, as well as Type and
+// Provider if the binding contains them.  Files named type or provider in the secret directory are not listed, as those
+// keys are read from the metadata directory.
+func (c CNBBinding) Keys() []string {
+	secret, err := listKeys(os.DirFS(filepath.Join(c.Root, "secret")), ".")
+	if err != nil {
+		secret = []string{}
+	}
+
+	keys := make([]string, 0, len(secret)+2)
+	for _, k := range secret {
+		if k != Type && k != Provider {
+			keys = append(keys, k)
+		}
+	}
+
+	for _, k := range []string{Type, Provider} {
+		if fi, err := os.Stat(c.path(k)); err == nil && fi.Mode().IsRegular() {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (c CNBBinding) path(key string) string {
+	switch key {
+	case Type:
+		return filepath.Join(c.Root, "metadata", "kind")
+	case Provider:
+		return filepath.Join(c.Root, "metadata", "provider")
+	default:
+		return filepath.Join(c.Root, "secret", key)
+	}
+}
+
+// FromCNB creates a collection of Bindings from the specified path, which uses the legacy Cloud Native Buildpacks
+// layout.  If the directory does not exist, an empty collection is returned.
+func FromCNB(root string) []Binding {
+	children, err := os.ReadDir(root)
+	if err != nil {
+		return []Binding{}
+	}
+
+	var bindings []Binding
+	for _, c := range children {
+		if !c.IsDir() {
+			continue
+		}
+
+		bindings = append(bindings, CNBBinding{Root: filepath.Join(root, c.Name())})
+	}
+
+	return bindings
+}
+
+// FromCNBBindings creates Bindings using the $CNB_BINDINGS environment variable to determine the file system root.  If
+// the $CNB_BINDINGS environment variable is not set, an empty collection is returned.  If the directory does not exist,
+// an empty collection is returned.
+func FromCNBBindings() []Binding {
+	path, ok := os.LookupEnv(CNBBindings)
+	if !ok {
+		return []Binding{}
+	}
+
+	return FromCNB(path)
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/nebhale/client-go/bindings"
+)
+
+func Test_CNBBinding_Type(t *testing.T) {
+	b := bindings.CNBBinding{Root: cnbRoot(t)}
+
+	if v, err := bindings.GetType(b); err != nil {
+		t.Errorf("does not identify type")
+	} else if v != "test-kind" {
+		t.Errorf("returned the wrong value")
+	}
+}
+
+func Test_CNBBinding_Provider(t *testing.T) {
+	b := bindings.CNBBinding{Root: cnbRoot(t)}
+
+	if v, ok := bindings.GetProvider(b); !ok {
+		t.Errorf("does not identify provider")
+	} else if v != "test-provider" {
+		t.Errorf("returned the wrong value")
+	}
+}
+
+func Test_CNBBinding_Valid(t *testing.T) {
+	b := bindings.CNBBinding{Root: cnbRoot(t)}
+
+	if v, ok := bindings.Get(b, "test-secret-key"); !ok {
+		t.Errorf("does not identify valid key")
+	} else if v != "test-secret-value" {
+		t.Errorf("returned the wrong value")
+	}
+}
+
+func Test_CNBBinding_Missing(t *testing.T) {
+	b := bindings.CNBBinding{Root: cnbRoot(t)}
+
+	if _, err := b.GetAsBytesE("test-missing-key"); !errors.Is(err, bindings.ErrNotFound) {
+		t.Errorf("does not identify missing key")
+	}
+}
+
+func Test_CNBBinding_Invalid(t *testing.T) {
+	b := bindings.CNBBinding{Root: cnbRoot(t)}
+
+	if _, ok := b.GetAsBytes("test^secret^key"); ok {
+		t.Errorf("does not identify invalid key")
+	}
+}
+
+func Test_CNBBinding_GetName(t *testing.T) {
+	b := bindings.CNBBinding{Root: cnbRoot(t)}
+
+	if b.GetName() != "test-name" {
+		t.Errorf("returned the wrong value")
+	}
+}
+
+func Test_CNBBinding_Keys(t *testing.T) {
+	b := bindings.CNBBinding{Root: cnbRoot(t)}
+
+	if !reflect.DeepEqual(b.Keys(), []string{"provider", "test-secret-key", "type"}) {
+		t.Errorf("returned the wrong value: %v", b.Keys())
+	}
+}
+
+func Test_CNBBinding_Keys_SecretMetadata(t *testing.T) {
+	root := cnbRoot(t)
+	writeEntry(t, filepath.Join(root, "secret"), "type", "test-secret-type\n")
+	writeEntry(t, filepath.Join(root, "secret"), "provider", "test-secret-provider\n")
+
+	b := bindings.CNBBinding{Root: root}
+
+	if !reflect.DeepEqual(b.Keys(), []string{"provider", "test-secret-key", "type"}) {
+		t.Errorf("returned the wrong value: %v", b.Keys())
+	}
+}
+
+func Test_FromCNB_Missing(t *testing.T) {
+	if !reflect.DeepEqual(bindings.FromCNB("missing"), []bindings.Binding{}) {
+		t.Errorf("did not create an empty Bindings")
+	}
+}
+
+func Test_FromCNB_Valid(t *testing.T) {
+	b := bindings.FromCNB(filepath.Dir(cnbRoot(t)))
+
+	if len(b) != 1 {
+		t.Fatalf("did not create proper number of bindings")
+	}
+	if len(bindings.Filter(b, "test-kind")) != 1 {
+		t.Errorf("did not filter on kind")
+	}
+}
+
+func Test_FromCNBBindings_Unset(t *testing.T) {
+	if !reflect.DeepEqual(bindings.FromCNBBindings(), []bindings.Binding{}) {
+		t.Errorf("did not create an empty Bindings")
+	}
+}
+
+func Test_FromCNBBindings_Set(t *testing.T) {
+	t.Setenv("CNB_BINDINGS", filepath.Dir(cnbRoot(t)))
+
+	if len(bindings.FromCNBBindings()) != 1 {
+		t.Errorf("did not create proper number of bindings")
+	}
+}
+
+func cnbRoot(t *testing.T) string {
+	t.Helper()
+
+	root := filepath.Join(t.TempDir(), "test-name")
+	writeEntry(t, filepath.Join(root, "metadata"), "kind", "test-kind\n")
+	writeEntry(t, filepath.Join(root, "metadata"), "provider", "test-provider\n")
+	writeEntry(t, filepath.Join(root, "secret"), "test-secret-key", "test-secret-value\n")
+
+	return root
+}
+
+func writeEntry(t *testing.T, dir string, key string, value string) {
+	t.Helper()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, key), []byte(value), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
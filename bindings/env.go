@@ -0,0 +1,124 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/nebhale/client-go/internal"
+)
+
+// EnvironmentPrefix is the prefix of environment variables that describe binding entries.
+const EnvironmentPrefix = "SERVICE_BINDING_"
+
+// environmentEscapes are the escape sequences for characters that are valid in a binding name or key but not in an
+// environment variable name.
+var environmentEscapes = map[byte]byte{
+	'D': '.',
+	'H': '-',
+	'U': '_',
+}
+
+// EnvironmentVariable returns the name of the environment variable that describes a binding entry.  The name and key
+// are separated by a double underscore and upper-cased, with ".", "-", and "_" escaped as "_D_", "_H_", and "_U_"
+// respectively.  For example, the ca.crt entry of the my-db binding is described by
+// SERVICE_BINDING_MY_H_DB__CA_D_CRT.
+func EnvironmentVariable(name string, key string) string {
+	escape := strings.NewReplacer(".", "_D_", "-", "_H_", "_", "_U_")
+	return EnvironmentPrefix + strings.ToUpper(escape.Replace(name)) + "__" + strings.ToUpper(escape.Replace(key))
+}
+
+// FromEnvironment creates a collection of Bindings from environment variables of the form
+// SERVICE_BINDING_<NAME>__<KEY>=<value>, as described by EnvironmentVariable.  Names and keys are lower-cased, and the
+// type and provider of a binding come from its TYPE and PROVIDER keys.  Variables that do not follow the convention,
+// such as SERVICE_BINDING_ROOT, are ignored.
+func FromEnvironment() []Binding {
+	return FromEnvironmentVariables(os.Environ())
+}
+
+// FromEnvironmentVariables creates a collection of Bindings from environment variables in the "key=value" form
+// returned by os.Environ.  See FromEnvironment for the convention.
+func FromEnvironmentVariables(environ []string) []Binding {
+	content := map[string]map[string][]byte{}
+
+	for _, e := range environ {
+		variable, value, ok := strings.Cut(e, "=")
+		if !ok || !strings.HasPrefix(variable, EnvironmentPrefix) {
+			continue
+		}
+
+		name, key, ok := parseEnvironmentVariable(strings.TrimPrefix(variable, EnvironmentPrefix))
+		if !ok {
+			continue
+		}
+
+		if content[name] == nil {
+			content[name] = map[string][]byte{}
+		}
+		content[name][key] = []byte(value)
+	}
+
+	bindings := []Binding{}
+	for n, c := range content {
+		bindings = append(bindings, MapBinding{Name: n, Content: c})
+	}
+	sort.Slice(bindings, func(i, j int) bool {
+		return bindings[i].GetName() < bindings[j].GetName()
+	})
+
+	return bindings
+}
+
+func parseEnvironmentVariable(s string) (string, string, bool) {
+	var (
+		b    strings.Builder
+		name string
+		key  bool
+	)
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '_' {
+			b.WriteString(strings.ToLower(s[i : i+1]))
+			continue
+		}
+
+		if !key && i+1 < len(s) && s[i+1] == '_' {
+			name, key = b.String(), true
+			b.Reset()
+			i++
+			continue
+		}
+
+		if i+2 < len(s) && s[i+2] == '_' {
+			if c, ok := environmentEscapes[s[i+1]]; ok {
+				b.WriteByte(c)
+				i += 2
+				continue
+			}
+		}
+
+		return "", "", false
+	}
+
+	if !key || name == "" || !internal.IsValidSecretKey(b.String()) {
+		return "", "", false
+	}
+
+	return name, b.String(), true
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings_test
+
+import (
+	"testing"
+
+	"github.com/nebhale/client-go/bindings"
+)
+
+func Test_EnvironmentVariable(t *testing.T) {
+	if v := bindings.EnvironmentVariable("my-db", "ca.crt"); v != "SERVICE_BINDING_MY_H_DB__CA_D_CRT" {
+		t.Errorf("returned the wrong value: %s", v)
+	}
+	if v := bindings.EnvironmentVariable("db", "d_x"); v != "SERVICE_BINDING_DB__D_U_X" {
+		t.Errorf("returned the wrong value: %s", v)
+	}
+}
+
+func Test_FromEnvironmentVariables_RoundTrip(t *testing.T) {
+	for _, c := range [][2]string{
+		{"my-db", "ca.crt"},
+		{"db", "d_x"},
+		{"a.", "key"},
+		{"a..b", "-key-"},
+		{"x_y", "sasl.jaas.config"},
+	} {
+		b := bindings.FromEnvironmentVariables([]string{bindings.EnvironmentVariable(c[0], c[1]) + "=test-value"})
+		if len(b) != 1 {
+			t.Errorf("did not parse %s %s", c[0], c[1])
+			continue
+		}
+
+		if b[0].GetName() != c[0] {
+			t.Errorf("returned the wrong name: %s", b[0].GetName())
+		}
+		if v, ok := bindings.Get(b[0], c[1]); !ok || v != "test-value" {
+			t.Errorf("returned the wrong value for %s", c[1])
+		}
+	}
+}
+
+func Test_FromEnvironmentVariables_Valid(t *testing.T) {
+	b := bindings.FromEnvironmentVariables([]string{
+		"SERVICE_BINDING_DB__TYPE=postgresql",
+		"SERVICE_BINDING_DB__PROVIDER=test-provider",
+		"SERVICE_BINDING_DB__PASSWORD=test=password",
+		"SERVICE_BINDING_CACHE__TYPE=redis",
+		"SERVICE_BINDING_ROOT=/bindings",
+		"HOME=/root",
+	})
+
+	if len(b) != 2 {
+		t.Fatalf("did not create proper number of bindings")
+	}
+
+	db, ok := bindings.Find(bindings.Filter(b, "postgresql"), "db")
+	if !ok {
+		t.Fatalf("did not filter on type")
+	}
+	if v, ok := bindings.GetProvider(db); !ok || v != "test-provider" {
+		t.Errorf("returned the wrong provider")
+	}
+	if v, ok := bindings.Get(db, "password"); !ok || v != "test=password" {
+		t.Errorf("returned the wrong value")
+	}
+}
+
+func Test_FromEnvironmentVariables_Invalid(t *testing.T) {
+	b := bindings.FromEnvironmentVariables([]string{
+		"SERVICE_BINDING_DB_TYPE=postgresql",
+		"SERVICE_BINDING___TYPE=postgresql",
+		"SERVICE_BINDING_DB__=postgresql",
+		"SERVICE_BINDING_DB__A__B=postgresql",
+		"SERVICE_BINDING_DB__A_X_B=postgresql",
+		"SERVICE_BINDING_DB__TYPE",
+	})
+
+	if len(b) != 0 {
+		t.Errorf("did not ignore invalid variables: %v", b)
+	}
+}
+
+func Test_FromEnvironment(t *testing.T) {
+	t.Setenv("SERVICE_BINDING_TEST_H_NAME__TYPE", "test-type-1")
+
+	if _, ok := bindings.Find(bindings.Filter(bindings.FromEnvironment(), "test-type-1"), "test-name"); !ok {
+		t.Errorf("did not create binding")
+	}
+}
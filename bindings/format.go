@@ -0,0 +1,258 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+// Redacted replaces the values of entries when a binding is formatted or logged.
+const Redacted = "[redacted]"
+
+// Unredacted wraps a binding so that it is formatted and logged with the values of its entries rather than redacting
+// them.  It is intended for local debugging only.  The returned binding implements KeyedBinding and ErrorBinding by
+// forwarding to the wrapped binding.
+func Unredacted(binding Binding) Binding {
+	return unredactedBinding{binding}
+}
+
+type unredactedBinding struct {
+	Binding
+}
+
+func (u unredactedBinding) GetAsBytesE(key string) ([]byte, error) {
+	return GetAsBytesE(u.Binding, key)
+}
+
+func (u unredactedBinding) Keys() []string {
+	k, _ := Keys(u.Binding)
+	return k
+}
+
+func (u unredactedBinding) String() string {
+	return describe(u.Binding, true, false)
+}
+
+func (u unredactedBinding) GoString() string {
+	return describe(u.Binding, true, true)
+}
+
+func (u unredactedBinding) Format(f fmt.State, verb rune) {
+	format(f, verb, u.Binding, true)
+}
+
+func (u unredactedBinding) LogValue() slog.Value {
+	return logValue(u.Binding, true)
+}
+
+func (c *CacheBinding) String() string {
+	return describe(c, false, false)
+}
+
+func (c *CacheBinding) GoString() string {
+	return describe(c, false, true)
+}
+
+func (c *CacheBinding) Format(f fmt.State, verb rune) {
+	format(f, verb, c, false)
+}
+
+func (c *CacheBinding) LogValue() slog.Value {
+	return logValue(c, false)
+}
+
+func (c CNBBinding) String() string {
+	return describe(c, false, false)
+}
+
+func (c CNBBinding) GoString() string {
+	return describe(c, false, true)
+}
+
+func (c CNBBinding) Format(f fmt.State, verb rune) {
+	format(f, verb, c, false)
+}
+
+func (c CNBBinding) LogValue() slog.Value {
+	return logValue(c, false)
+}
+
+func (c ConfigTreeBinding) String() string {
+	return describe(c, false, false)
+}
+
+func (c ConfigTreeBinding) GoString() string {
+	return describe(c, false, true)
+}
+
+func (c ConfigTreeBinding) Format(f fmt.State, verb rune) {
+	format(f, verb, c, false)
+}
+
+func (c ConfigTreeBinding) LogValue() slog.Value {
+	return logValue(c, false)
+}
+
+func (f FSBinding) String() string {
+	return describe(f, false, false)
+}
+
+func (f FSBinding) GoString() string {
+	return describe(f, false, true)
+}
+
+func (f FSBinding) Format(s fmt.State, verb rune) {
+	format(s, verb, f, false)
+}
+
+func (f FSBinding) LogValue() slog.Value {
+	return logValue(f, false)
+}
+
+func (m MapBinding) String() string {
+	return describe(m, false, false)
+}
+
+func (m MapBinding) GoString() string {
+	return describe(m, false, true)
+}
+
+func (m MapBinding) Format(f fmt.State, verb rune) {
+	format(f, verb, m, false)
+}
+
+func (m MapBinding) LogValue() slog.Value {
+	return logValue(m, false)
+}
+
+func (o OverlayBinding) String() string {
+	return describe(o, false, false)
+}
+
+func (o OverlayBinding) GoString() string {
+	return describe(o, false, true)
+}
+
+func (o OverlayBinding) Format(f fmt.State, verb rune) {
+	format(f, verb, o, false)
+}
+
+func (o OverlayBinding) LogValue() slog.Value {
+	return logValue(o, false)
+}
+
+// describe renders a binding as its name, type, provider, and the keys of its other entries.  If reveal is true, the
+// values of the entries are rendered as well.  If syntax is true, the type is qualified by its package and strings are
+// quoted for %#v.  The rendering resembles a composite literal but, as the entries are not fields, it is not valid Go
+// syntax.
+func describe(binding Binding, reveal bool, syntax bool) string {
+	t := reflect.Indirect(reflect.ValueOf(binding)).Type()
+
+	q := func(s string) string {
+		if syntax {
+			return fmt.Sprintf("%q", s)
+		}
+		return s
+	}
+
+	var s strings.Builder
+
+	if syntax {
+		_, _ = fmt.Fprintf(&s, "%s{Name: %s", t, q(binding.GetName()))
+	} else {
+		_, _ = fmt.Fprintf(&s, "%s{Name: %s", t.Name(), binding.GetName())
+	}
+
+	if v, ok := Get(binding, Type); ok {
+		_, _ = fmt.Fprintf(&s, ", Type: %s", q(v))
+	}
+
+	if v, ok := GetProvider(binding); ok {
+		_, _ = fmt.Fprintf(&s, ", Provider: %s", q(v))
+	}
+
+	_, _ = fmt.Fprint(&s, ", Entries: {")
+	for i, k := range entryKeys(binding) {
+		if i > 0 {
+			_, _ = fmt.Fprint(&s, ", ")
+		}
+
+		v := Redacted
+		if reveal {
+			v, _ = Get(binding, k)
+		}
+		_, _ = fmt.Fprintf(&s, "%s: %s", q(k), q(v))
+	}
+	_, _ = fmt.Fprint(&s, "}}")
+
+	return s.String()
+}
+
+// format implements fmt.Formatter so that every verb, not only those that use fmt.Stringer, renders a described
+// binding.
+func format(f fmt.State, verb rune, binding Binding, reveal bool) {
+	switch {
+	case verb == 'v' && f.Flag('#'):
+		_, _ = fmt.Fprint(f, describe(binding, reveal, true))
+	case verb == 'q':
+		_, _ = fmt.Fprintf(f, "%q", describe(binding, reveal, false))
+	default:
+		_, _ = fmt.Fprint(f, describe(binding, reveal, false))
+	}
+}
+
+func logValue(binding Binding, reveal bool) slog.Value {
+	attrs := []slog.Attr{slog.String("name", binding.GetName())}
+
+	if v, ok := Get(binding, Type); ok {
+		attrs = append(attrs, slog.String("type", v))
+	}
+
+	if v, ok := GetProvider(binding); ok {
+		attrs = append(attrs, slog.String("provider", v))
+	}
+
+	keys := entryKeys(binding)
+	entries := make([]slog.Attr, 0, len(keys))
+	for _, k := range keys {
+		v := Redacted
+		if reveal {
+			v, _ = Get(binding, k)
+		}
+		entries = append(entries, slog.String(k, v))
+	}
+	attrs = append(attrs, slog.Attr{Key: "entries", Value: slog.GroupValue(entries...)})
+
+	return slog.GroupValue(attrs...)
+}
+
+// entryKeys returns the keys of a binding other than Type and Provider, which are rendered separately.
+func entryKeys(binding Binding) []string {
+	keys, _ := Keys(binding)
+
+	var e []string
+	for _, k := range keys {
+		if k != Type && k != Provider {
+			e = append(e, k)
+		}
+	}
+
+	return e
+}
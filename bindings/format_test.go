@@ -0,0 +1,138 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nebhale/client-go/bindings"
+)
+
+var testFormatBinding = bindings.MapBinding{
+	Name: "test-name",
+	Content: map[string][]byte{
+		"type":            []byte("test-type-1"),
+		"provider":        []byte("test-provider-1"),
+		"test-secret-key": []byte("test-secret-value"),
+	},
+}
+
+func Test_Format_Redacted(t *testing.T) {
+	for _, f := range []string{"%v", "%+v", "%s", "%q", "%#v", "%d", "%x"} {
+		s := fmt.Sprintf(f, testFormatBinding)
+		if strings.Contains(s, "test-secret-value") {
+			t.Errorf("%s did not redact value: %s", f, s)
+		}
+	}
+}
+
+func Test_Format_String(t *testing.T) {
+	e := "MapBinding{Name: test-name, Type: test-type-1, Provider: test-provider-1, Entries: {test-secret-key: [redacted]}}"
+	if s := testFormatBinding.String(); s != e {
+		t.Errorf("returned the wrong value: %s", s)
+	}
+}
+
+func Test_Format_GoString(t *testing.T) {
+	e := `bindings.MapBinding{Name: "test-name", Type: "test-type-1", Provider: "test-provider-1", ` +
+		`Entries: {"test-secret-key": "[redacted]"}}`
+	if s := fmt.Sprintf("%#v", testFormatBinding); s != e {
+		t.Errorf("returned the wrong value: %s", s)
+	}
+}
+
+func Test_Format_Types(t *testing.T) {
+	root := filepath.Join("testdata", "test-k8s")
+
+	for _, b := range []bindings.Binding{
+		&bindings.CacheBinding{Delegate: bindings.ConfigTreeBinding{Root: root}},
+		bindings.CNBBinding{Root: cnbRoot(t)},
+		bindings.ConfigTreeBinding{Root: root},
+		bindings.FSBinding{FS: testFS, Root: "bindings/test-name-1"},
+	} {
+		s := fmt.Sprintf("%v", b)
+		if strings.Contains(s, "test-secret-value") {
+			t.Errorf("did not redact value: %s", s)
+		}
+		if !strings.Contains(s, "test-secret-key: [redacted]") {
+			t.Errorf("did not list key: %s", s)
+		}
+	}
+}
+
+func Test_Format_Unredacted(t *testing.T) {
+	s := fmt.Sprintf("%v", bindings.Unredacted(testFormatBinding))
+	if !strings.Contains(s, "test-secret-key: test-secret-value") {
+		t.Errorf("did not reveal value: %s", s)
+	}
+}
+
+func Test_Format_Unredacted_Forwards(t *testing.T) {
+	b := bindings.Unredacted(testFormatBinding)
+
+	if k, ok := bindings.Keys(b); !ok || len(k) != 3 {
+		t.Errorf("did not forward keys: %v", k)
+	}
+	if _, err := bindings.GetAsBytesE(b, "test-missing-key"); !errors.Is(err, bindings.ErrNotFound) {
+		t.Errorf("did not forward error: %v", err)
+	}
+}
+
+func Test_Format_CacheBinding_Value(t *testing.T) {
+	c := &struct{ Binding bindings.CacheBinding }{bindings.CacheBinding{Delegate: testFormatBinding}}
+	if _, ok := c.Binding.GetAsBytes("test-secret-key"); !ok {
+		t.Fatalf("did not retrieve value")
+	}
+
+	if s := fmt.Sprintf("%+v", c); strings.Contains(s, "test-secret-value") || strings.Contains(s, "116 101 115 116") {
+		t.Errorf("did not redact cached value: %s", s)
+	}
+}
+
+func Test_LogValue_Redacted(t *testing.T) {
+	var b bytes.Buffer
+	slog.New(slog.NewTextHandler(&b, nil)).Info("test-message", "binding", testFormatBinding)
+
+	s := b.String()
+	if strings.Contains(s, "test-secret-value") {
+		t.Errorf("did not redact value: %s", s)
+	}
+	if !strings.Contains(s, "binding.name=test-name binding.type=test-type-1 binding.provider=test-provider-1") {
+		t.Errorf("did not log binding: %s", s)
+	}
+	if !strings.Contains(s, "binding.entries.test-secret-key=[redacted]") {
+		t.Errorf("did not log key: %s", s)
+	}
+	if strings.Contains(s, "binding.entries.type") || strings.Contains(s, "binding.entries.provider") {
+		t.Errorf("logged type and provider twice: %s", s)
+	}
+}
+
+func Test_LogValue_Unredacted(t *testing.T) {
+	var b bytes.Buffer
+	slog.New(slog.NewTextHandler(&b, nil)).Info("test-message", "binding", bindings.Unredacted(testFormatBinding))
+
+	if s := b.String(); !strings.Contains(s, "binding.entries.test-secret-key=test-secret-value") {
+		t.Errorf("did not reveal value: %s", s)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings
+
+import (
+	"io/fs"
+	"path"
+
+	"github.com/nebhale/client-go/internal"
+)
+
+// FSBinding is an implementation of the Binding interface that reads files from an fs.FS, such as an embed.FS or an
+// fstest.MapFS.  It has the same semantics as ConfigTreeBinding.
+type FSBinding struct {
+
+	// FS is the filesystem containing the binding.
+	FS fs.FS
+
+	// Root is the path of the binding within FS.
+	Root string
+}
+
+func (f FSBinding) GetAsBytes(key string) ([]byte, bool) {
+	b, err := f.GetAsBytesE(key)
+	return b, err == nil
+}
+
+func (f FSBinding) GetAsBytesE(key string) ([]byte, error) {
+	if !internal.IsValidSecretKey(key) {
+		return nil, keyError(f, key, ErrInvalidKey)
+	}
+
+	b, err := Restrictions{}.readFS(f.FS, path.Join(f.Root, key))
+	if err != nil {
+		return nil, entryError(f, key, err)
+	}
+
+	return b, nil
+}
+
+func (f FSBinding) GetName() string {
+	return path.Base(f.Root)
+}
+
+// Keys returns the names of the regular files in the binding that are valid secret keys.  Hidden entries such as the
+// Kubernetes ..data symlink and directories are skipped.
+func (f FSBinding) Keys() []string {
+	keys, err := listKeys(f.FS, f.Root)
+	if err != nil {
+		return []string{}
+	}
+
+	return keys
+}
+
+// FromFS creates a collection of Bindings from the specified directory of an fs.FS.  If the directory does not exist,
+// an empty collection is returned.
+func FromFS(fsys fs.FS, dir string) []Binding {
+	children, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return []Binding{}
+	}
+
+	var bindings []Binding
+	for _, c := range children {
+		if !c.IsDir() {
+			continue
+		}
+
+		bindings = append(bindings, FSBinding{FS: fsys, Root: path.Join(dir, c.Name())})
+	}
+
+	return bindings
+}
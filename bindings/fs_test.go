@@ -0,0 +1,116 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nebhale/client-go/bindings"
+)
+
+var testFS = fstest.MapFS{
+	"bindings/test-name-1/type":                   {Data: []byte("test-type-1\n")},
+	"bindings/test-name-1/provider":               {Data: []byte("test-provider-1\n")},
+	"bindings/test-name-1/test-secret-key":        {Data: []byte("test-secret-value\n")},
+	"bindings/test-name-1/.hidden-data/type":      {Data: []byte("test-type-1\n")},
+	"bindings/test-name-1/..data/test-secret-key": {Data: []byte("test-secret-value\n")},
+	"bindings/test-name-2/type":                   {Data: []byte("test-type-2\n")},
+	"bindings/additional-file":                    {Data: []byte{}},
+}
+
+func Test_FSBinding_Missing(t *testing.T) {
+	b := bindings.FSBinding{FS: testFS, Root: "bindings/test-name-1"}
+
+	if _, err := b.GetAsBytesE("test-missing-key"); !errors.Is(err, bindings.ErrNotFound) {
+		t.Errorf("does not identify missing key")
+	}
+}
+
+func Test_FSBinding_Directory(t *testing.T) {
+	b := bindings.FSBinding{FS: testFS, Root: "bindings/test-name-1"}
+
+	if _, err := b.GetAsBytesE(".hidden-data"); !errors.Is(err, bindings.ErrNotRegularFile) {
+		t.Errorf("does not identify directory")
+	}
+}
+
+func Test_FSBinding_Invalid(t *testing.T) {
+	b := bindings.FSBinding{FS: testFS, Root: "bindings/test-name-1"}
+
+	if _, ok := b.GetAsBytes("test^secret^key"); ok {
+		t.Errorf("does not identify invalid key")
+	}
+}
+
+func Test_FSBinding_Valid(t *testing.T) {
+	b := bindings.FSBinding{FS: testFS, Root: "bindings/test-name-1"}
+
+	if v, ok := bindings.Get(b, "test-secret-key"); !ok {
+		t.Errorf("does not identify valid key")
+	} else if v != "test-secret-value" {
+		t.Errorf("returned the wrong value")
+	}
+}
+
+func Test_FSBinding_GetName(t *testing.T) {
+	b := bindings.FSBinding{FS: testFS, Root: "bindings/test-name-1"}
+
+	if b.GetName() != "test-name-1" {
+		t.Errorf("returned the wrong value")
+	}
+}
+
+func Test_FSBinding_Keys(t *testing.T) {
+	b := bindings.FSBinding{FS: testFS, Root: "bindings/test-name-1"}
+
+	if !reflect.DeepEqual(b.Keys(), []string{"provider", "test-secret-key", "type"}) {
+		t.Errorf("returned the wrong value: %v", b.Keys())
+	}
+}
+
+func Test_FromFS_Missing(t *testing.T) {
+	if !reflect.DeepEqual(bindings.FromFS(testFS, "missing"), []bindings.Binding{}) {
+		t.Errorf("did not create an empty Bindings")
+	}
+}
+
+func Test_FromFS_File(t *testing.T) {
+	if !reflect.DeepEqual(bindings.FromFS(testFS, "bindings/additional-file"), []bindings.Binding{}) {
+		t.Errorf("did not create an empty Bindings")
+	}
+}
+
+func Test_FromFS_Valid(t *testing.T) {
+	b := bindings.FromFS(testFS, "bindings")
+
+	if len(b) != 2 {
+		t.Fatalf("did not create proper number of bindings")
+	}
+	if len(bindings.Filter(b, "test-type-2")) != 1 {
+		t.Errorf("did not filter on type")
+	}
+}
+
+func Test_FromFS_DirFS(t *testing.T) {
+	if len(bindings.FromFS(os.DirFS("testdata"), ".")) != 3 {
+		t.Errorf("did not create proper number of bindings")
+	}
+}
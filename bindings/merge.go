@@ -0,0 +1,157 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CollisionPolicy determines how Merge resolves bindings with the same name.  Names are compared case-insensitively.
+type CollisionPolicy int
+
+const (
+
+	// CollisionFirstWins keeps the binding that appears first.
+	CollisionFirstWins CollisionPolicy = iota
+
+	// CollisionLastWins keeps the binding that appears last.
+	CollisionLastWins
+
+	// CollisionFail returns an error wrapping ErrDuplicateBinding.
+	CollisionFail
+
+	// CollisionOverlay combines the bindings into an OverlayBinding, so that each entry is read from the last binding
+	// that contains it.
+	CollisionOverlay
+)
+
+// ErrDuplicateBinding is returned by Merge when more than one binding has the same name and the policy is
+// CollisionFail.
+var ErrDuplicateBinding = errors.New("duplicate binding")
+
+// Merge combines layers of bindings from any source, such as From, FromVCAPServices, or a slice of MapBindings.  Layers
+// are given in increasing order of precedence, and bindings with the same name are resolved according to the policy.
+// The returned bindings are in the order in which their names first appear.
+func Merge(policy CollisionPolicy, layers ...[]Binding) ([]Binding, error) {
+	var (
+		names  []string
+		merged = map[string][]Binding{}
+	)
+
+	for _, l := range layers {
+		for _, b := range l {
+			n := strings.ToLower(b.GetName())
+
+			if _, ok := merged[n]; !ok {
+				names = append(names, n)
+			} else if policy == CollisionFail {
+				return nil, fmt.Errorf("%w: %s", ErrDuplicateBinding, b.GetName())
+			}
+
+			merged[n] = append(merged[n], b)
+		}
+	}
+
+	var bindings []Binding
+	for _, n := range names {
+		m := merged[n]
+
+		switch {
+		case len(m) == 1 || policy == CollisionFirstWins:
+			bindings = append(bindings, m[0])
+		case policy == CollisionLastWins:
+			bindings = append(bindings, m[len(m)-1])
+		default:
+			bindings = append(bindings, OverlayBinding{Layers: m})
+		}
+	}
+
+	return bindings, nil
+}
+
+// FromRoots creates a collection of Bindings from multiple file system roots, given in increasing order of precedence,
+// and combines them with Merge.  Roots that do not exist contribute no bindings.
+func FromRoots(policy CollisionPolicy, roots ...string) ([]Binding, error) {
+	layers := make([][]Binding, 0, len(roots))
+	for _, r := range roots {
+		layers = append(layers, From(r))
+	}
+
+	return Merge(policy, layers...)
+}
+
+// OverlayBinding is an implementation of the Binding interface that reads each entry from the last of its layers that
+// contains it, allowing an override binding to replace only some of the entries of a base binding.
+type OverlayBinding struct {
+
+	// Layers are the bindings to overlay, in increasing order of precedence.  The name of the binding is the name of the
+	// first layer.
+	Layers []Binding
+}
+
+func (o OverlayBinding) GetAsBytes(key string) ([]byte, bool) {
+	v, err := o.GetAsBytesE(key)
+	return v, err == nil
+}
+
+func (o OverlayBinding) GetAsBytesE(key string) ([]byte, error) {
+	err := keyError(o, key, ErrNotFound)
+
+	for i := len(o.Layers) - 1; i >= 0; i-- {
+		v, e := GetAsBytesE(o.Layers[i], key)
+		if e == nil {
+			return v, nil
+		}
+
+		if !errors.Is(e, ErrNotFound) {
+			return nil, e
+		}
+		err = e
+	}
+
+	return nil, err
+}
+
+func (o OverlayBinding) GetName() string {
+	if len(o.Layers) == 0 {
+		return ""
+	}
+
+	return o.Layers[0].GetName()
+}
+
+// Keys returns the union of the keys of the layers that implement KeyedBinding.
+func (o OverlayBinding) Keys() []string {
+	union := map[string]bool{}
+	for _, l := range o.Layers {
+		k, _ := Keys(l)
+		for _, c := range k {
+			union[c] = true
+		}
+	}
+
+	keys := make([]string, 0, len(union))
+	for k := range union {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
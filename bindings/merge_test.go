@@ -0,0 +1,173 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/nebhale/client-go/bindings"
+	"github.com/nebhale/client-go/bindings/bindingstest"
+)
+
+var (
+	testBase = []bindings.Binding{
+		bindings.MapBinding{
+			Name: "test-name-1",
+			Content: map[string][]byte{
+				"type":     []byte("test-type-1"),
+				"username": []byte("test-username"),
+				"password": []byte("test-password"),
+			},
+		},
+		bindings.MapBinding{Name: "test-name-2"},
+	}
+
+	testOverride = []bindings.Binding{
+		bindings.MapBinding{
+			Name: "TEST-NAME-1",
+			Content: map[string][]byte{
+				"password": []byte("test-password-2"),
+			},
+		},
+		bindings.MapBinding{Name: "test-name-3"},
+	}
+)
+
+func Test_Merge_FirstWins(t *testing.T) {
+	b, err := bindings.Merge(bindings.CollisionFirstWins, testBase, testOverride)
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	if !reflect.DeepEqual(b, []bindings.Binding{testBase[0], testBase[1], testOverride[1]}) {
+		t.Errorf("returned the wrong bindings: %v", b)
+	}
+}
+
+func Test_Merge_LastWins(t *testing.T) {
+	b, err := bindings.Merge(bindings.CollisionLastWins, testBase, testOverride)
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	if !reflect.DeepEqual(b, []bindings.Binding{testOverride[0], testBase[1], testOverride[1]}) {
+		t.Errorf("returned the wrong bindings: %v", b)
+	}
+}
+
+func Test_Merge_Fail(t *testing.T) {
+	_, err := bindings.Merge(bindings.CollisionFail, testBase, testOverride)
+	if !errors.Is(err, bindings.ErrDuplicateBinding) {
+		t.Errorf("does not identify duplicate binding")
+	}
+}
+
+func Test_Merge_Fail_Unique(t *testing.T) {
+	b, err := bindings.Merge(bindings.CollisionFail, testBase, testOverride[1:])
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	if len(b) != 3 {
+		t.Errorf("did not create proper number of bindings")
+	}
+}
+
+func Test_Merge_Overlay(t *testing.T) {
+	b, err := bindings.Merge(bindings.CollisionOverlay, testBase, testOverride)
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	c, ok := bindings.Find(b, "test-name-1")
+	if !ok {
+		t.Fatalf("did not find binding")
+	}
+
+	if c.GetName() != "test-name-1" {
+		t.Errorf("returned the wrong name")
+	}
+	if v, _ := bindings.Get(c, "password"); v != "test-password-2" {
+		t.Errorf("did not override entry")
+	}
+	if v, _ := bindings.Get(c, "username"); v != "test-username" {
+		t.Errorf("did not retain entry")
+	}
+	if len(bindings.Filter(b, "test-type-1")) != 1 {
+		t.Errorf("did not retain type")
+	}
+}
+
+func Test_OverlayBinding_Missing(t *testing.T) {
+	b := bindings.OverlayBinding{Layers: testBase[:1]}
+
+	if _, err := b.GetAsBytesE("test-missing-key"); !errors.Is(err, bindings.ErrNotFound) {
+		t.Errorf("does not identify missing key")
+	}
+}
+
+func Test_OverlayBinding_Invalid(t *testing.T) {
+	b := bindings.OverlayBinding{Layers: testBase[:1]}
+
+	if _, err := b.GetAsBytesE("test^invalid^key"); !errors.Is(err, bindings.ErrInvalidKey) {
+		t.Errorf("does not identify invalid key")
+	}
+}
+
+func Test_OverlayBinding_Empty(t *testing.T) {
+	b := bindings.OverlayBinding{}
+
+	if b.GetName() != "" {
+		t.Errorf("returned the wrong name")
+	}
+	if _, ok := b.GetAsBytes("test-secret-key"); ok {
+		t.Errorf("does not identify missing key")
+	}
+}
+
+func Test_OverlayBinding_Keys(t *testing.T) {
+	b := bindings.OverlayBinding{Layers: []bindings.Binding{testBase[0], testOverride[0]}}
+
+	if !reflect.DeepEqual(b.Keys(), []string{"password", "type", "username"}) {
+		t.Errorf("returned the wrong keys: %v", b.Keys())
+	}
+}
+
+func Test_FromRoots(t *testing.T) {
+	base := bindingstest.NewRoot(t)
+	base.Binding("test-name-1").Type("test-type-1").Entry("password", "test-password")
+
+	override := bindingstest.NewRoot(t)
+	override.Binding("test-name-1").Entry("password", "test-password-2")
+	override.Binding("test-name-2").Type("test-type-2")
+
+	b, err := bindings.FromRoots(bindings.CollisionOverlay, base.Path(), "missing", override.Path())
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	if len(b) != 2 {
+		t.Fatalf("did not create proper number of bindings")
+	}
+	if c, _ := bindings.Find(b, "test-name-1"); c == nil {
+		t.Errorf("did not find binding")
+	} else if v, _ := bindings.Get(c, "password"); v != "test-password-2" {
+		t.Errorf("did not override entry")
+	}
+}
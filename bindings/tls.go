@@ -0,0 +1,144 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// CACertificate is the key for the PEM encoded certificate authorities used to verify a peer.
+const CACertificate = "ca.crt"
+
+// Certificate is the key for the PEM encoded certificate presented to a peer.
+const Certificate = "tls.crt"
+
+// PrivateKey is the key for the PEM encoded private key of the Certificate.
+const PrivateKey = "tls.key"
+
+// ServerName is the key for the name used to verify the hostname of a server.
+const ServerName = "server-name"
+
+// Insecure is the key for whether verification of a server's certificate chain and hostname is skipped.
+const Insecure = "insecure"
+
+// HasTLS returns whether a binding contains any of the CACertificate, Certificate, or PrivateKey entries.
+func HasTLS(binding Binding) bool {
+	for _, k := range []string{CACertificate, Certificate, PrivateKey} {
+		if _, ok := binding.GetAsBytes(k); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TLSConfig creates a *tls.Config from the CACertificate, Certificate, PrivateKey, ServerName, and Insecure entries of
+// a binding.  Entries that are not present are left unconfigured.
+//
+// The certificate and private key are re-read during each handshake through GetCertificate and GetClientCertificate so
+// that rotated certificates take effect without a restart.  They are only re-parsed if their content has changed, and
+// if a rotated pair cannot be loaded, the last valid pair is used.
+func TLSConfig(binding Binding) (*tls.Config, error) {
+	c := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if v, ok := binding.GetAsBytes(CACertificate); ok {
+		p, err := parseCertPool(v)
+		if err != nil {
+			return nil, keyError(binding, CACertificate, err)
+		}
+		c.RootCAs = p
+	}
+
+	if v, ok := Get(binding, ServerName); ok {
+		c.ServerName = v
+	}
+
+	if v, ok := Get(binding, Insecure); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, keyError(binding, Insecure, err)
+		}
+		c.InsecureSkipVerify = b
+	}
+
+	if _, ok := binding.GetAsBytes(Certificate); !ok {
+		return c, nil
+	}
+
+	k := &keyPairLoader{binding: binding}
+	if _, err := k.load(); err != nil {
+		return nil, err
+	}
+
+	c.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return k.load()
+	}
+	c.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		return k.load()
+	}
+
+	return c, nil
+}
+
+// keyPairLoader loads the Certificate and PrivateKey of a binding, only re-parsing them when their content changes.
+type keyPairLoader struct {
+	binding Binding
+
+	certificate []byte
+	key         []byte
+	parsed      *tls.Certificate
+	mutex       sync.Mutex
+}
+
+func (k *keyPairLoader) load() (*tls.Certificate, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	c, err := GetAsBytesE(k.binding, Certificate)
+	if err != nil {
+		return k.previous(err)
+	}
+
+	p, err := GetAsBytesE(k.binding, PrivateKey)
+	if err != nil {
+		return k.previous(err)
+	}
+
+	if k.parsed != nil && bytes.Equal(c, k.certificate) && bytes.Equal(p, k.key) {
+		return k.parsed, nil
+	}
+
+	t, err := tls.X509KeyPair(c, p)
+	if err != nil {
+		return k.previous(fmt.Errorf("binding %s: unable to load key pair: %w", k.binding.GetName(), err))
+	}
+
+	k.certificate, k.key, k.parsed = c, p, &t
+	return k.parsed, nil
+}
+
+func (k *keyPairLoader) previous(err error) (*tls.Certificate, error) {
+	if k.parsed != nil {
+		return k.parsed, nil
+	}
+
+	return nil, err
+}
@@ -0,0 +1,168 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings_test
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"testing"
+
+	"github.com/nebhale/client-go/bindings"
+	"github.com/nebhale/client-go/bindings/bindingstest"
+)
+
+func Test_HasTLS(t *testing.T) {
+	if bindings.HasTLS(bindings.MapBinding{Name: "test-name"}) {
+		t.Errorf("identified missing entries")
+	}
+
+	for _, k := range []string{bindings.CACertificate, bindings.Certificate, bindings.PrivateKey} {
+		if !bindings.HasTLS(bindings.MapBinding{Name: "test-name", Content: map[string][]byte{k: []byte("test")}}) {
+			t.Errorf("did not identify %s", k)
+		}
+	}
+}
+
+func Test_TLSConfig_Empty(t *testing.T) {
+	c, err := bindings.TLSConfig(bindings.MapBinding{Name: "test-name"})
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	if c.RootCAs != nil || len(c.Certificates) != 0 || c.GetClientCertificate != nil {
+		t.Errorf("configured missing entries")
+	}
+}
+
+func Test_TLSConfig_Static(t *testing.T) {
+	cert, key := bindingstest.Certificate(t)
+
+	c, err := bindings.TLSConfig(bindings.MapBinding{
+		Name: "test-name",
+		Content: map[string][]byte{
+			"ca.crt":      cert,
+			"tls.crt":     cert,
+			"tls.key":     key,
+			"server-name": []byte("test-host\n"),
+			"insecure":    []byte("true"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	if c.RootCAs == nil {
+		t.Errorf("did not configure root certificate authorities")
+	}
+	if c.GetCertificate == nil || c.GetClientCertificate == nil {
+		t.Errorf("did not configure certificate")
+	}
+	if c.ServerName != "test-host" {
+		t.Errorf("did not configure server name")
+	}
+	if !c.InsecureSkipVerify {
+		t.Errorf("did not configure insecure")
+	}
+}
+
+func Test_TLSConfig_Reload_Cached(t *testing.T) {
+	cert, key := bindingstest.Certificate(t)
+	b := bindingstest.NewRoot(t).Binding("test-name").EntryBytes("tls.crt", cert).EntryBytes("tls.key", key)
+
+	c, err := bindings.TLSConfig(&bindings.CacheBinding{
+		Delegate:   bindings.ConfigTreeBinding{Root: b.Path()},
+		Revalidate: true,
+	})
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	first, err := c.GetClientCertificate(&tls.CertificateRequestInfo{})
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	cert, key = bindingstest.Certificate(t)
+	b.EntryBytes("tls.crt", cert).EntryBytes("tls.key", key)
+
+	second, err := c.GetClientCertificate(&tls.CertificateRequestInfo{})
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+	if bytes.Equal(first.Certificate[0], second.Certificate[0]) {
+		t.Errorf("did not reload certificate")
+	}
+}
+
+func Test_TLSConfig_InvalidCA(t *testing.T) {
+	_, err := bindings.TLSConfig(bindings.MapBinding{
+		Name:    "test-name",
+		Content: map[string][]byte{"ca.crt": []byte("test-invalid")},
+	})
+	if err == nil {
+		t.Errorf("does not identify invalid certificate authority")
+	}
+}
+
+func Test_TLSConfig_MissingKey(t *testing.T) {
+	cert, _ := bindingstest.Certificate(t)
+
+	_, err := bindings.TLSConfig(bindings.MapBinding{
+		Name:    "test-name",
+		Content: map[string][]byte{"tls.crt": cert},
+	})
+	if !errors.Is(err, bindings.ErrNotFound) {
+		t.Errorf("does not identify missing private key")
+	}
+}
+
+func Test_TLSConfig_Reload(t *testing.T) {
+	cert, key := bindingstest.Certificate(t)
+	b := bindingstest.NewRoot(t).Binding("test-name").EntryBytes("tls.crt", cert).EntryBytes("tls.key", key)
+
+	c, err := bindings.TLSConfig(bindings.ConfigTreeBinding{Root: b.Path()})
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	first, err := c.GetClientCertificate(&tls.CertificateRequestInfo{})
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	cert, key = bindingstest.Certificate(t)
+	b.EntryBytes("tls.crt", cert).EntryBytes("tls.key", key)
+
+	second, err := c.GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+	if bytes.Equal(first.Certificate[0], second.Certificate[0]) {
+		t.Errorf("did not reload certificate")
+	}
+
+	b.Remove("tls.key")
+
+	third, err := c.GetClientCertificate(&tls.CertificateRequestInfo{})
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+	if !bytes.Equal(second.Certificate[0], third.Certificate[0]) {
+		t.Errorf("did not use last valid certificate")
+	}
+}
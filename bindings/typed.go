@@ -0,0 +1,128 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetBool returns the contents of a binding entry parsed as a bool with strconv.ParseBool.  The returned error names
+// the binding and key if the entry could not be retrieved or parsed.
+func GetBool(binding Binding, key string) (bool, error) {
+	s, err := GetE(binding, key)
+	if err != nil {
+		return false, err
+	}
+
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, keyError(binding, key, err)
+	}
+
+	return b, nil
+}
+
+// GetDuration returns the contents of a binding entry parsed as a duration with time.ParseDuration.  The returned error
+// names the binding and key if the entry could not be retrieved or parsed.
+func GetDuration(binding Binding, key string) (time.Duration, error) {
+	s, err := GetE(binding, key)
+	if err != nil {
+		return 0, err
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, keyError(binding, key, err)
+	}
+
+	return d, nil
+}
+
+// GetInt returns the contents of a binding entry parsed as a decimal int.  The returned error names the binding and key
+// if the entry could not be retrieved or parsed.
+func GetInt(binding Binding, key string) (int, error) {
+	s, err := GetE(binding, key)
+	if err != nil {
+		return 0, err
+	}
+
+	i, err := strconv.ParseInt(s, 10, strconv.IntSize)
+	if err != nil {
+		return 0, keyError(binding, key, err)
+	}
+
+	return int(i), nil
+}
+
+// GetJSON decodes the contents of a binding entry as JSON into the value pointed to by v.  The returned error names
+// the binding and key if the entry could not be retrieved or decoded.
+func GetJSON(binding Binding, key string, v any) error {
+	b, err := GetAsBytesE(binding, key)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(b, v); err != nil {
+		return keyError(binding, key, err)
+	}
+
+	return nil
+}
+
+// GetStringSlice returns the contents of a binding entry split on commas and newlines.  Whitespace is trimmed from each
+// element and empty elements are removed.  The returned error names the binding and key if the entry could not be
+// retrieved.
+func GetStringSlice(binding Binding, key string) ([]string, error) {
+	s, err := GetE(binding, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseStringSlice(s), nil
+}
+
+// GetURL returns the contents of a binding entry parsed as a URL with url.Parse.  The returned error names the binding
+// and key if the entry could not be retrieved or parsed.
+func GetURL(binding Binding, key string) (*url.URL, error) {
+	s, err := GetE(binding, key)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, keyError(binding, key, err)
+	}
+
+	return u, nil
+}
+
+// parseStringSlice splits a comma or newline separated list.
+func parseStringSlice(s string) []string {
+	v := []string{}
+	for _, e := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if e = strings.TrimSpace(e); e != "" {
+			v = append(v, e)
+		}
+	}
+
+	return v
+}
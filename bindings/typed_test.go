@@ -0,0 +1,121 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nebhale/client-go/bindings"
+)
+
+var typedBinding = bindings.MapBinding{
+	Name: "test-name",
+	Content: map[string][]byte{
+		"bool":     []byte("true\n"),
+		"duration": []byte("1m30s"),
+		"int":      []byte("010"),
+		"json":     []byte(`{"test-key": "test-value"}`),
+		"slice":    []byte("test-1, test-2\ntest-3,,\n"),
+		"url":      []byte("https://test-host:8443/test-path\n"),
+		"invalid":  []byte("test-invalid%"),
+	},
+}
+
+func Test_GetBool(t *testing.T) {
+	if v, err := bindings.GetBool(typedBinding, "bool"); err != nil || !v {
+		t.Errorf("returned the wrong value: %t, %v", v, err)
+	}
+}
+
+func Test_GetDuration(t *testing.T) {
+	if v, err := bindings.GetDuration(typedBinding, "duration"); err != nil || v != 90*time.Second {
+		t.Errorf("returned the wrong value: %s, %v", v, err)
+	}
+}
+
+func Test_GetInt(t *testing.T) {
+	if v, err := bindings.GetInt(typedBinding, "int"); err != nil || v != 10 {
+		t.Errorf("returned the wrong value: %d, %v", v, err)
+	}
+}
+
+func Test_GetJSON(t *testing.T) {
+	var v map[string]string
+	if err := bindings.GetJSON(typedBinding, "json", &v); err != nil {
+		t.Fatal(err)
+	}
+	if v["test-key"] != "test-value" {
+		t.Errorf("returned the wrong value: %v", v)
+	}
+}
+
+func Test_GetStringSlice(t *testing.T) {
+	v, err := bindings.GetStringSlice(typedBinding, "slice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v, []string{"test-1", "test-2", "test-3"}) {
+		t.Errorf("returned the wrong value: %v", v)
+	}
+}
+
+func Test_GetURL(t *testing.T) {
+	v, err := bindings.GetURL(typedBinding, "url")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Hostname() != "test-host" || v.Port() != "8443" || v.Path != "/test-path" {
+		t.Errorf("returned the wrong value: %s", v)
+	}
+}
+
+func Test_Typed_Missing(t *testing.T) {
+	errs := []error{
+		func() error { _, err := bindings.GetBool(typedBinding, "missing"); return err }(),
+		func() error { _, err := bindings.GetDuration(typedBinding, "missing"); return err }(),
+		func() error { _, err := bindings.GetInt(typedBinding, "missing"); return err }(),
+		bindings.GetJSON(typedBinding, "missing", &map[string]string{}),
+		func() error { _, err := bindings.GetStringSlice(typedBinding, "missing"); return err }(),
+		func() error { _, err := bindings.GetURL(typedBinding, "missing"); return err }(),
+	}
+
+	for _, err := range errs {
+		if !errors.Is(err, bindings.ErrNotFound) {
+			t.Errorf("does not identify missing entry: %v", err)
+		}
+	}
+}
+
+func Test_Typed_Invalid(t *testing.T) {
+	errs := []error{
+		func() error { _, err := bindings.GetBool(typedBinding, "invalid"); return err }(),
+		func() error { _, err := bindings.GetDuration(typedBinding, "invalid"); return err }(),
+		func() error { _, err := bindings.GetInt(typedBinding, "invalid"); return err }(),
+		bindings.GetJSON(typedBinding, "invalid", &map[string]string{}),
+		func() error { _, err := bindings.GetURL(typedBinding, "invalid"); return err }(),
+	}
+
+	for _, err := range errs {
+		if err == nil || !strings.Contains(err.Error(), "binding test-name key invalid") {
+			t.Errorf("does not name binding and key: %v", err)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldError describes a struct field that could not be decoded by Unmarshal.
+type FieldError struct {
+
+	// Field is the dotted path of the struct field.
+	Field string
+
+	// Key is the binding key the field is mapped to.
+	Key string
+
+	// Err is the reason the field could not be decoded.
+	Err error
+}
+
+func (f *FieldError) Error() string {
+	return fmt.Sprintf("field %s (key %s): %v", f.Field, f.Key, f.Err)
+}
+
+func (f *FieldError) Unwrap() error {
+	return f.Err
+}
+
+var (
+	certPoolType = reflect.TypeOf(x509.CertPool{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	urlType      = reflect.TypeOf(url.URL{})
+)
+
+// Unmarshal decodes the entries of a binding into the struct pointed to by v.  Fields are mapped to keys with the
+// "binding" struct tag, for example:
+//
+//	type Config struct {
+//		Host    string         `binding:"host,required"`
+//		Port    int            `binding:"port"`
+//		Timeout time.Duration  `binding:"timeout"`
+//		CA      *x509.CertPool `binding:"ca.crt"`
+//	}
+//
+// Fields without a tag or with an empty name in their tag, such as `binding:",required"`, are mapped to their
+// lower-cased name and fields tagged "-" are skipped.  Supported field types
+// are string, []string, []byte, bool, signed and unsigned integers, floats, time.Duration, url.URL, x509.CertPool,
+// pointers to those types, and nested structs.  A []string is decoded from a comma or newline separated list, as with
+// GetStringSlice.  Nested structs are decoded from keys prefixed with the field's key and a "." unless they are
+// embedded or tagged with an empty name, in which case they are decoded from the same keys as their parent.  An empty
+// name only has this meaning for nested structs.
+//
+// Values other than []byte and x509.CertPool have whitespace trimmed, as with Get.  Missing keys are an error only for
+// fields tagged "required".  Every field that cannot be decoded is reported as a *FieldError in the returned error.
+func Unmarshal(binding Binding, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("unmarshal target must be a non-nil pointer to a struct, not %T", v)
+	}
+
+	return errors.Join(unmarshalStruct(binding, rv.Elem(), "", "")...)
+}
+
+func unmarshalStruct(binding Binding, v reflect.Value, prefix string, path string) []error {
+	var errs []error
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		tag, ok := f.Tag.Lookup("binding")
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if !ok {
+			name = strings.ToLower(f.Name)
+		}
+		required := options == "required"
+
+		field := f.Name
+		if path != "" {
+			field = path + "." + f.Name
+		}
+
+		if isNested(f.Type) {
+			p := prefix
+			if !f.Anonymous && name != "" {
+				p = prefix + name + "."
+			}
+			errs = append(errs, unmarshalStruct(binding, v.Field(i), p, field)...)
+			continue
+		}
+
+		// as with encoding/json, a tag such as ",required" keeps the default name
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		key := prefix + name
+
+		raw, err := GetAsBytesE(binding, key)
+		if errors.Is(err, ErrNotFound) && !required {
+			continue
+		} else if err != nil {
+			errs = append(errs, &FieldError{Field: field, Key: key, Err: err})
+			continue
+		}
+
+		if err := decode(v.Field(i), raw); err != nil {
+			errs = append(errs, &FieldError{Field: field, Key: key, Err: err})
+		}
+	}
+
+	return errs
+}
+
+func isNested(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != urlType && t != certPoolType
+}
+
+func decode(v reflect.Value, raw []byte) error {
+	t := v.Type()
+
+	switch {
+	case t == reflect.PointerTo(certPoolType):
+		p, err := parseCertPool(raw)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(p))
+		return nil
+	case t == reflect.PointerTo(urlType):
+		u, err := url.Parse(strings.TrimSpace(string(raw)))
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(u))
+		return nil
+	case t.Kind() == reflect.Pointer:
+		e := reflect.New(t.Elem())
+		if err := decode(e.Elem(), raw); err != nil {
+			return err
+		}
+		v.Set(e)
+		return nil
+	case t == certPoolType:
+		p, err := parseCertPool(raw)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(p).Elem())
+		return nil
+	case t == urlType:
+		u, err := url.Parse(strings.TrimSpace(string(raw)))
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(u).Elem())
+		return nil
+	case t == durationType:
+		d, err := time.ParseDuration(strings.TrimSpace(string(raw)))
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		v.SetBytes(raw)
+		return nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String:
+		s := parseStringSlice(string(raw))
+		e := reflect.MakeSlice(t, len(s), len(s))
+		for i := range s {
+			e.Index(i).SetString(s[i])
+		}
+		v.Set(e)
+		return nil
+	}
+
+	s := strings.TrimSpace(string(raw))
+
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", t)
+	}
+
+	return nil
+}
+
+func parseCertPool(raw []byte) (*x509.CertPool, error) {
+	p := x509.NewCertPool()
+	if !p.AppendCertsFromPEM(raw) {
+		return nil, fmt.Errorf("no PEM encoded certificates found")
+	}
+
+	return p, nil
+}
@@ -0,0 +1,203 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings_test
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nebhale/client-go/bindings"
+	"github.com/nebhale/client-go/bindings/bindingstest"
+)
+
+func Test_Unmarshal_Types(t *testing.T) {
+	ca, _ := bindingstest.Certificate(t)
+
+	b := bindings.MapBinding{
+		Name: "test-name",
+		Content: map[string][]byte{
+			"host":     []byte("test-host\n"),
+			"port":     []byte("5432\n"),
+			"enabled":  []byte("true"),
+			"ratio":    []byte("0.5"),
+			"count":    []byte("7"),
+			"timeout":  []byte("5s"),
+			"url":      []byte("https://test-host/test-path"),
+			"password": []byte("test-password\n"),
+			"ca.crt":   ca,
+			"optional": []byte("test-optional"),
+			"hosts":    []byte("test-host-1, test-host-2\n"),
+		},
+	}
+
+	var c struct {
+		Host     string         `binding:"host,required"`
+		Port     int            `binding:"port"`
+		Enabled  bool           `binding:"enabled"`
+		Ratio    float64        `binding:"ratio"`
+		Count    uint8          `binding:"count"`
+		Timeout  time.Duration  `binding:"timeout"`
+		URL      *url.URL       `binding:"url"`
+		Password []byte         `binding:"password"`
+		CA       *x509.CertPool `binding:"ca.crt"`
+		Optional *string        `binding:"optional"`
+		Absent   *string        `binding:"absent"`
+		Hosts    []string       `binding:"hosts"`
+		Skipped  string         `binding:"-"`
+	}
+
+	if err := bindings.Unmarshal(b, &c); err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	if c.Host != "test-host" || c.Port != 5432 || !c.Enabled || c.Ratio != 0.5 || c.Count != 7 || c.Timeout != 5*time.Second {
+		t.Errorf("returned the wrong scalar values: %+v", c)
+	}
+	if c.URL == nil || c.URL.Host != "test-host" {
+		t.Errorf("returned the wrong URL")
+	}
+	if !bytes.Equal(c.Password, []byte("test-password\n")) {
+		t.Errorf("trimmed []byte value")
+	}
+	if c.CA == nil {
+		t.Errorf("did not create certificate pool")
+	}
+	if c.Optional == nil || *c.Optional != "test-optional" {
+		t.Errorf("did not set optional value")
+	}
+	if c.Absent != nil {
+		t.Errorf("set absent value")
+	}
+	if !reflect.DeepEqual(c.Hosts, []string{"test-host-1", "test-host-2"}) {
+		t.Errorf("returned the wrong slice: %v", c.Hosts)
+	}
+}
+
+func Test_Unmarshal_Decimal(t *testing.T) {
+	b := bindings.MapBinding{
+		Name:    "test-name",
+		Content: map[string][]byte{"signed": []byte("010"), "unsigned": []byte("08")},
+	}
+
+	var c struct {
+		Signed   int  `binding:"signed"`
+		Unsigned uint `binding:"unsigned"`
+	}
+
+	if err := bindings.Unmarshal(b, &c); err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+	if c.Signed != 10 || c.Unsigned != 8 {
+		t.Errorf("did not parse decimal values: %+v", c)
+	}
+}
+
+func Test_Unmarshal_EmptyName(t *testing.T) {
+	var c struct {
+		Host string `binding:",required"`
+		Port string `binding:",required"`
+	}
+
+	err := bindings.Unmarshal(bindings.MapBinding{
+		Name:    "test-name",
+		Content: map[string][]byte{"host": []byte("test-host")},
+	}, &c)
+
+	if c.Host != "test-host" {
+		t.Errorf("returned the wrong value: %s", c.Host)
+	}
+
+	var f *bindings.FieldError
+	if !errors.As(err, &f) || f.Key != "port" || !errors.Is(err, bindings.ErrNotFound) {
+		t.Errorf("does not identify missing required key: %v", err)
+	}
+}
+
+func Test_Unmarshal_Nested(t *testing.T) {
+	b := bindings.MapBinding{
+		Name: "test-name",
+		Content: map[string][]byte{
+			"host":           []byte("test-host"),
+			"username":       []byte("test-username"),
+			"sasl.mechanism": []byte("PLAIN"),
+		},
+	}
+
+	type Credentials struct {
+		Username string
+	}
+
+	var c struct {
+		Host string
+		Credentials
+		SASL struct {
+			Mechanism string
+		} `binding:"sasl"`
+	}
+
+	if err := bindings.Unmarshal(b, &c); err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	if c.Host != "test-host" || c.Username != "test-username" || c.SASL.Mechanism != "PLAIN" {
+		t.Errorf("returned the wrong values: %+v", c)
+	}
+}
+
+func Test_Unmarshal_Errors(t *testing.T) {
+	b := bindings.MapBinding{
+		Name: "test-name",
+		Content: map[string][]byte{
+			"port":    []byte("test-port"),
+			"timeout": []byte("test-timeout"),
+		},
+	}
+
+	var c struct {
+		Host    string        `binding:"host,required"`
+		Port    int           `binding:"port"`
+		Timeout time.Duration `binding:"timeout"`
+	}
+
+	err := bindings.Unmarshal(b, &c)
+	if !errors.Is(err, bindings.ErrNotFound) {
+		t.Errorf("does not identify missing required key")
+	}
+
+	n := 0
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var f *bindings.FieldError
+		if errors.As(e, &f) {
+			n++
+		}
+	}
+	if n != 3 {
+		t.Errorf("did not report all errors: %v", err)
+	}
+}
+
+func Test_Unmarshal_InvalidTarget(t *testing.T) {
+	var s string
+	if err := bindings.Unmarshal(bindings.MapBinding{}, &s); err == nil {
+		t.Errorf("does not identify invalid target")
+	}
+}
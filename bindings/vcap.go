@@ -0,0 +1,169 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VCAPServices is the name of the environment variable read to determine the Cloud Foundry service bindings.
+const VCAPServices = "VCAP_SERVICES"
+
+// userProvided is the label of Cloud Foundry user-provided service instances.
+const userProvided = "user-provided"
+
+var invalidSecretKeyCharacters = regexp.MustCompile(`[^A-Za-z0-9\-_.]`)
+
+type vcapService struct {
+	Name        string          `json:"name"`
+	Label       string          `json:"label"`
+	Plan        string          `json:"plan"`
+	Provider    *string         `json:"provider"`
+	Tags        []string        `json:"tags"`
+	Credentials json.RawMessage `json:"credentials"`
+}
+
+// FromVCAP creates a collection of Bindings from the contents of a Cloud Foundry VCAP_SERVICES variable.  Each service
+// instance becomes a MapBinding named after the instance.  The Type is the label of the service, or the first tag for
+// user-provided services, and the Provider is the provider of the service, or its label if there is none.  Credentials
+// are flattened into keys, with nested objects and arrays joined with ".", and characters that are not valid in a
+// secret key replaced with "_".  The label, plan, and comma separated tags are also available as keys.  If the
+// credentials already contain a type, provider, label, plan, or tags key, the credential value is kept.
+func FromVCAP(vcap string) ([]Binding, error) {
+	var services map[string][]vcapService
+	if err := json.Unmarshal([]byte(vcap), &services); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", VCAPServices, err)
+	}
+
+	var bindings []Binding
+	for label, instances := range services {
+		for _, s := range instances {
+			content := map[string][]byte{}
+
+			if len(s.Credentials) > 0 {
+				d := json.NewDecoder(strings.NewReader(string(s.Credentials)))
+				d.UseNumber()
+
+				var credentials any
+				if err := d.Decode(&credentials); err != nil {
+					return nil, fmt.Errorf("unable to parse credentials of %s: %w", s.Name, err)
+				}
+				flatten(content, "", credentials)
+			}
+
+			if s.Label == "" {
+				s.Label = label
+			}
+
+			// credentials take precedence over the keys derived from the service instance
+			set := func(key string, value string) {
+				if _, ok := content[key]; !ok {
+					content[key] = []byte(value)
+				}
+			}
+
+			set("label", s.Label)
+
+			if s.Plan != "" {
+				set("plan", s.Plan)
+			}
+
+			if len(s.Tags) > 0 {
+				set("tags", strings.Join(s.Tags, ","))
+			}
+
+			if s.Label == userProvided && len(s.Tags) > 0 {
+				set(Type, s.Tags[0])
+			} else {
+				set(Type, s.Label)
+			}
+
+			if s.Provider != nil && *s.Provider != "" {
+				set(Provider, *s.Provider)
+			} else {
+				set(Provider, s.Label)
+			}
+
+			bindings = append(bindings, MapBinding{Name: s.Name, Content: content})
+		}
+	}
+
+	sort.SliceStable(bindings, func(i, j int) bool {
+		return bindings[i].GetName() < bindings[j].GetName()
+	})
+
+	return bindings, nil
+}
+
+// FromVCAPServices creates Bindings using the $VCAP_SERVICES environment variable.  If the $VCAP_SERVICES environment
+// variable is not set or cannot be parsed, an empty collection is returned.
+func FromVCAPServices() []Binding {
+	vcap, ok := os.LookupEnv(VCAPServices)
+	if !ok {
+		return []Binding{}
+	}
+
+	bindings, err := FromVCAP(vcap)
+	if err != nil {
+		return []Binding{}
+	}
+
+	return bindings
+}
+
+func flatten(content map[string][]byte, prefix string, value any) {
+	join := func(key string) string {
+		key = invalidSecretKeyCharacters.ReplaceAllString(key, "_")
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	var s string
+
+	switch v := value.(type) {
+	case map[string]any:
+		for k, c := range v {
+			flatten(content, join(k), c)
+		}
+		return
+	case []any:
+		for i, c := range v {
+			flatten(content, join(strconv.Itoa(i)), c)
+		}
+		return
+	case string:
+		s = v
+	case json.Number:
+		s = v.String()
+	case bool:
+		s = strconv.FormatBool(v)
+	default:
+		return
+	}
+
+	if prefix != "" {
+		content[prefix] = []byte(s)
+	}
+}
@@ -0,0 +1,238 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DefaultWatchInterval is the polling interval used by Watch.
+const DefaultWatchInterval = 2 * time.Second
+
+// EventType is the kind of change described by an Event.
+type EventType int
+
+const (
+
+	// Added indicates that a binding has appeared in the watched root.
+	Added EventType = iota
+
+	// Removed indicates that a binding has disappeared from the watched root.
+	Removed
+
+	// Updated indicates that the content of a binding has changed.
+	Updated
+)
+
+func (e EventType) String() string {
+	switch e {
+	case Added:
+		return "Added"
+	case Removed:
+		return "Removed"
+	case Updated:
+		return "Updated"
+	default:
+		return "Unknown"
+	}
+}
+
+// Event is a change to a binding in a watched root.
+type Event struct {
+
+	// Type is the kind of change.
+	Type EventType
+
+	// Binding is the binding that changed.
+	Binding Binding
+
+	// Keys are the keys that were added, removed, or changed.  For Added and Removed events, this is every key in the
+	// binding.
+	Keys []string
+}
+
+// Watcher polls a bindings file system root for changes.  Bindings that use the Kubernetes atomic ..data symlink swap
+// are only re-read when the symlink changes, and then are read from a single generation.
+type Watcher struct {
+
+	// Root is the filesystem root of the bindings.
+	Root string
+
+	// Interval is the time between polls.  If zero, DefaultWatchInterval is used.
+	Interval time.Duration
+}
+
+// Watch polls the specified path for changes to the bindings within it.  Equivalent to a Watcher with the default
+// interval.
+func Watch(ctx context.Context, root string) <-chan Event {
+	return Watcher{Root: root}.Watch(ctx)
+}
+
+// Watch polls for changes to bindings until the context is cancelled, at which point the returned channel is closed.
+// Bindings that exist when watching starts are reported as Added.  If the root does not exist, it is treated as empty
+// until it is created.
+func (w Watcher) Watch(ctx context.Context) <-chan Event {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	events := make(chan Event)
+
+	go func() {
+		defer close(events)
+
+		t := time.NewTicker(interval)
+		defer t.Stop()
+
+		previous := map[string]watchState{}
+		for {
+			current := scan(w.Root, previous)
+
+			for _, e := range diff(w.Root, previous, current) {
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+			previous = current
+
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events
+}
+
+// watchState is the observed state of a single binding.
+type watchState struct {
+	generation string
+	entries    map[string][sha256.Size]byte
+}
+
+func scan(root string, previous map[string]watchState) map[string]watchState {
+	current := map[string]watchState{}
+
+	children, err := os.ReadDir(root)
+	if err != nil {
+		return current
+	}
+
+	for _, c := range children {
+		if !c.IsDir() {
+			continue
+		}
+
+		n := c.Name()
+		p, seen := previous[n]
+
+		if seen && p.generation != "" {
+			if g, err := os.Readlink(filepath.Join(root, n, dataDir)); err == nil && g == p.generation {
+				current[n] = p
+				continue
+			}
+		}
+
+		g, content, err := snapshotTree(filepath.Join(root, n), Restrictions{})
+		if err != nil {
+			if seen {
+				current[n] = p
+			}
+			continue
+		}
+
+		s := watchState{generation: g, entries: make(map[string][sha256.Size]byte, len(content))}
+		for k, v := range content {
+			s.entries[k] = sha256.Sum256(v)
+		}
+		current[n] = s
+	}
+
+	return current
+}
+
+func diff(root string, previous map[string]watchState, current map[string]watchState) []Event {
+	var events []Event
+
+	for n, c := range current {
+		p, ok := previous[n]
+		if !ok {
+			events = append(events, Event{
+				Type:    Added,
+				Binding: ConfigTreeBinding{Root: filepath.Join(root, n)},
+				Keys:    sortedKeys(c.entries),
+			})
+			continue
+		}
+
+		var changed []string
+		for k, v := range c.entries {
+			if o, ok := p.entries[k]; !ok || o != v {
+				changed = append(changed, k)
+			}
+		}
+		for k := range p.entries {
+			if _, ok := c.entries[k]; !ok {
+				changed = append(changed, k)
+			}
+		}
+
+		if len(changed) > 0 {
+			sort.Strings(changed)
+			events = append(events, Event{
+				Type:    Updated,
+				Binding: ConfigTreeBinding{Root: filepath.Join(root, n)},
+				Keys:    changed,
+			})
+		}
+	}
+
+	for n, p := range previous {
+		if _, ok := current[n]; !ok {
+			events = append(events, Event{
+				Type:    Removed,
+				Binding: ConfigTreeBinding{Root: filepath.Join(root, n)},
+				Keys:    sortedKeys(p.entries),
+			})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Binding.GetName() < events[j].Binding.GetName()
+	})
+
+	return events
+}
+
+func sortedKeys(entries map[string][sha256.Size]byte) []string {
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
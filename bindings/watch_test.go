@@ -0,0 +1,167 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings_test
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nebhale/client-go/bindings"
+	"github.com/nebhale/client-go/bindings/bindingstest"
+)
+
+func Test_EventType_String(t *testing.T) {
+	if bindings.Added.String() != "Added" || bindings.Removed.String() != "Removed" || bindings.Updated.String() != "Updated" {
+		t.Errorf("returned the wrong value")
+	}
+}
+
+func Test_Watch_Existing(t *testing.T) {
+	r := bindingstest.NewRoot(t)
+	r.Binding("test-name-1").Type("test-type-1").Entry("test-secret-key", "test-secret-value")
+
+	events := watch(t, r.Path())
+
+	e := nextEvent(t, events)
+	if e.Type != bindings.Added || e.Binding.GetName() != "test-name-1" {
+		t.Errorf("did not report existing binding")
+	}
+	if !reflect.DeepEqual(e.Keys, []string{"test-secret-key", "type"}) {
+		t.Errorf("returned the wrong keys: %v", e.Keys)
+	}
+}
+
+func Test_Watch_Added(t *testing.T) {
+	r := bindingstest.NewRoot(t)
+	events := watch(t, r.Path())
+
+	r.Binding("test-name-1").Type("test-type-1")
+
+	if e := nextEvent(t, events); e.Type != bindings.Added || e.Binding.GetName() != "test-name-1" {
+		t.Errorf("did not report added binding")
+	}
+}
+
+func Test_Watch_MissingRoot(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "missing")
+	events := watch(t, root)
+
+	writeEntry(t, filepath.Join(root, "test-name-1"), "type", "test-type-1")
+
+	if e := nextEvent(t, events); e.Type != bindings.Added {
+		t.Errorf("did not report added binding")
+	}
+}
+
+func Test_Watch_Updated(t *testing.T) {
+	r := bindingstest.NewRoot(t)
+	b := r.Binding("test-name-1").Type("test-type-1").Entry("test-secret-key", "test-secret-value")
+
+	events := watch(t, r.Path())
+	nextEvent(t, events)
+
+	b.Entry("test-secret-key", "test-secret-value-2")
+
+	e := nextEvent(t, events)
+	if e.Type != bindings.Updated {
+		t.Errorf("did not report updated binding")
+	}
+	if !reflect.DeepEqual(e.Keys, []string{"test-secret-key"}) {
+		t.Errorf("returned the wrong keys: %v", e.Keys)
+	}
+	if v, ok := bindings.Get(e.Binding, "test-secret-key"); !ok || v != "test-secret-value-2" {
+		t.Errorf("did not return live binding")
+	}
+}
+
+func Test_Watch_Removed(t *testing.T) {
+	r := bindingstest.NewRoot(t)
+	r.Binding("test-name-1").Type("test-type-1")
+
+	events := watch(t, r.Path())
+	nextEvent(t, events)
+
+	r.Remove("test-name-1")
+
+	e := nextEvent(t, events)
+	if e.Type != bindings.Removed || e.Binding.GetName() != "test-name-1" {
+		t.Errorf("did not report removed binding")
+	}
+	if !reflect.DeepEqual(e.Keys, []string{"type"}) {
+		t.Errorf("returned the wrong keys: %v", e.Keys)
+	}
+}
+
+func Test_Watch_Kubernetes(t *testing.T) {
+	r := bindingstest.NewRoot(t).Kubernetes()
+	b := r.Binding("test-name-1").Type("test-type-1").Entry("test-secret-key", "test-secret-value")
+
+	events := watch(t, r.Path())
+
+	if e := nextEvent(t, events); !reflect.DeepEqual(e.Keys, []string{"test-secret-key", "type"}) {
+		t.Errorf("returned the wrong keys: %v", e.Keys)
+	}
+
+	b.Entry("test-secret-key", "test-secret-value-2")
+
+	e := nextEvent(t, events)
+	if e.Type != bindings.Updated {
+		t.Errorf("did not report updated binding")
+	}
+	if !reflect.DeepEqual(e.Keys, []string{"test-secret-key"}) {
+		t.Errorf("returned the wrong keys: %v", e.Keys)
+	}
+}
+
+func Test_Watch_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	events := bindings.Watcher{Root: t.TempDir(), Interval: time.Millisecond}.Watch(ctx)
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Errorf("did not close channel")
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("did not close channel")
+	}
+}
+
+func watch(t *testing.T, root string) <-chan bindings.Event {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	return bindings.Watcher{Root: root, Interval: 10 * time.Millisecond}.Watch(ctx)
+}
+
+func nextEvent(t *testing.T, events <-chan bindings.Event) bindings.Event {
+	t.Helper()
+
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatalf("did not receive event")
+		return bindings.Event{}
+	}
+}
@@ -0,0 +1,225 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/nebhale/client-go/bindings"
+)
+
+// mapping exports a binding entry as an environment variable.
+type mapping struct {
+	variable string
+	binding  string
+	key      string
+}
+
+// mappings is a flag.Value that accumulates VARIABLE=binding:key mappings.
+type mappings []mapping
+
+func (m *mappings) String() string {
+	s := make([]string, 0, len(*m))
+	for _, c := range *m {
+		s = append(s, fmt.Sprintf("%s=%s:%s", c.variable, c.binding, c.key))
+	}
+
+	return strings.Join(s, ",")
+}
+
+func (m *mappings) Set(value string) error {
+	variable, entry, ok := strings.Cut(value, "=")
+	if !ok || variable == "" {
+		return fmt.Errorf("mapping %q must be of the form VARIABLE=binding:key", value)
+	}
+
+	binding, key, ok := strings.Cut(entry, ":")
+	if !ok || binding == "" || key == "" {
+		return fmt.Errorf("mapping %q must be of the form VARIABLE=binding:key", value)
+	}
+
+	*m = append(*m, mapping{variable: variable, binding: binding, key: key})
+	return nil
+}
+
+// forwarded are the signals received by this process that are forwarded to the child.
+var forwarded = []os.Signal{syscall.SIGHUP, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM}
+
+// defaultGracePeriod is the time a command is given to exit after SIGTERM when restarting, before it is killed.
+const defaultGracePeriod = 10 * time.Second
+
+func execCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	f, root := flags("exec", stderr)
+
+	var m mappings
+	f.Var(&m, "map", "export a binding entry as VARIABLE=binding:key (repeatable)")
+	restart := f.Bool("restart", false, "restart the command when a mapped entry changes")
+	interval := f.Duration("interval", bindings.DefaultWatchInterval,
+		"interval between checks for changes when restarting")
+	grace := f.Duration("grace", defaultGracePeriod,
+		"time the command is given to exit after SIGTERM when restarting, before it is killed")
+
+	if err := f.Parse(args); err != nil {
+		return 2
+	}
+
+	if f.NArg() == 0 {
+		_, _ = fmt.Fprintln(stderr, "Usage: bindings exec [flags] -- <command> [arguments]")
+		return 2
+	}
+
+	if *root == "" {
+		_, _ = fmt.Fprintf(stderr, "No bindings root: set $%s or -root\n", bindings.ServiceBindingRoot)
+		return 1
+	}
+
+	env, err := resolve(*root, m)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwarded...)
+	defer signal.Stop(signals)
+
+	var events <-chan bindings.Event
+	if *restart {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		events = bindings.Watcher{Root: *root, Interval: *interval}.Watch(ctx)
+	}
+
+	for {
+		cmd := exec.Command(f.Arg(0), f.Args()[1:]...)
+		cmd.Env = append(os.Environ(), env...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, stdout, stderr
+
+		if err := cmd.Start(); err != nil {
+			_, _ = fmt.Fprintf(stderr, "%v\n", err)
+			return 1
+		}
+
+		done := make(chan error, 1)
+		go func() {
+			done <- cmd.Wait()
+		}()
+
+		restarting := false
+
+		// kill fires once the grace period of a restart has elapsed
+		var (
+			timer *time.Timer
+			kill  <-chan time.Time
+		)
+
+	wait:
+		for {
+			select {
+			case s := <-signals:
+				_ = cmd.Process.Signal(s)
+
+				// the command is not restarted once it has been told to terminate
+				if s != syscall.SIGHUP {
+					restarting = false
+				}
+			case <-events:
+				e, err := resolve(*root, m)
+				if err != nil || slices.Equal(e, env) {
+					continue
+				}
+
+				env, restarting = e, true
+				_, _ = fmt.Fprintln(stderr, "Bindings changed, restarting command")
+				_ = cmd.Process.Signal(syscall.SIGTERM)
+
+				if timer == nil {
+					timer = time.NewTimer(*grace)
+					kill = timer.C
+				}
+			case <-kill:
+				_, _ = fmt.Fprintf(stderr, "Command did not exit within %s, killing\n", *grace)
+				_ = cmd.Process.Kill()
+				kill = nil
+			case err := <-done:
+				if timer != nil {
+					timer.Stop()
+				}
+
+				if restarting {
+					break wait
+				}
+				return exitCode(err)
+			}
+		}
+	}
+}
+
+// resolve returns the environment variables for the mappings from the bindings in a root.
+func resolve(root string, m mappings) ([]string, error) {
+	b, err := bindings.FromE(root)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make([]string, 0, len(m))
+	for _, c := range m {
+		binding, ok := bindings.Find(b, c.binding)
+		if !ok {
+			return nil, fmt.Errorf("no binding named %q", c.binding)
+		}
+
+		v, err := bindings.GetE(binding, c.key)
+		if err != nil {
+			return nil, err
+		}
+
+		env = append(env, fmt.Sprintf("%s=%s", c.variable, v))
+	}
+
+	return env, nil
+}
+
+// exitCode returns the exit code of a completed command, using the shell convention of 128 plus the signal number for
+// a command terminated by a signal.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var e *exec.ExitError
+	if !errors.As(err, &e) {
+		return 1
+	}
+
+	if s, ok := e.Sys().(syscall.WaitStatus); ok && s.Signaled() {
+		return 128 + int(s.Signal())
+	}
+
+	return e.ExitCode()
+}
@@ -0,0 +1,233 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/nebhale/client-go/bindings/bindingstest"
+)
+
+// Test_HelperProcess is not a test, but the child process run by the exec tests.
+func Test_HelperProcess(t *testing.T) {
+	if os.Getenv("TEST_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	if os.Getenv("TEST_IGNORE_SIGTERM") == "1" {
+		signal.Ignore(syscall.SIGTERM)
+	}
+
+	fmt.Printf("TEST_VALUE=%s\n", os.Getenv("TEST_VALUE"))
+
+	if w, ok := os.LookupEnv("TEST_WAIT_UNLESS"); ok && w != os.Getenv("TEST_VALUE") {
+		time.Sleep(30 * time.Second)
+	}
+
+	code, _ := strconv.Atoi(os.Getenv("TEST_EXIT_CODE"))
+	os.Exit(code)
+}
+
+func Test_Mappings_Set(t *testing.T) {
+	var m mappings
+
+	if err := m.Set("DATABASE_URL=db:url"); err != nil {
+		t.Errorf("returned an error: %v", err)
+	}
+	if m.String() != "DATABASE_URL=db:url" {
+		t.Errorf("returned the wrong value: %s", m.String())
+	}
+
+	for _, i := range []string{"DATABASE_URL", "=db:url", "DATABASE_URL=db", "DATABASE_URL=:url", "DATABASE_URL=db:"} {
+		if err := m.Set(i); err == nil {
+			t.Errorf("does not identify invalid mapping %q", i)
+		}
+	}
+}
+
+func Test_Exec_NoCommand(t *testing.T) {
+	if execute(t, "exec", "-root", testRoot) != 2 {
+		t.Errorf("did not fail")
+	}
+}
+
+func Test_Exec_MissingBinding(t *testing.T) {
+	if execute(t, "exec", "-root", testRoot, "-map", "TEST_VALUE=test-missing:test-secret-key", "--", "true") != 1 {
+		t.Errorf("did not fail")
+	}
+}
+
+func Test_Exec_MissingKey(t *testing.T) {
+	if execute(t, "exec", "-root", testRoot, "-map", "TEST_VALUE=test-name-1:test-missing", "--", "true") != 1 {
+		t.Errorf("did not fail")
+	}
+}
+
+func Test_Exec_Environment(t *testing.T) {
+	t.Setenv("TEST_HELPER_PROCESS", "1")
+	t.Setenv("TEST_EXIT_CODE", "3")
+
+	out, code := capture(t, "exec", "-root", testRoot, "--map", "TEST_VALUE=test-name-1:test-secret-key",
+		"--", os.Args[0], "-test.run=^Test_HelperProcess$")
+
+	if code != 3 {
+		t.Errorf("did not propagate exit code: %d", code)
+	}
+	if !strings.Contains(out, "TEST_VALUE=test-secret-value\n") {
+		t.Errorf("did not export entry: %s", out)
+	}
+}
+
+func Test_Exec_Restart(t *testing.T) {
+	b := bindingstest.NewRoot(t).Kubernetes().Binding("test-name-1").Entry("test-secret-key", "test-secret-value-1")
+
+	t.Setenv("TEST_HELPER_PROCESS", "1")
+	t.Setenv("TEST_WAIT_UNLESS", "test-secret-value-2")
+
+	var stdout, stderr syncBuffer
+	result := make(chan int, 1)
+	go func() {
+		result <- run([]string{"exec", "-root", filepath.Dir(b.Path()), "-restart", "-interval", "10ms",
+			"-map", "TEST_VALUE=test-name-1:test-secret-key", "--", os.Args[0], "-test.run=^Test_HelperProcess$"},
+			&stdout, &stderr)
+	}()
+
+	waitFor(t, &stdout, "TEST_VALUE=test-secret-value-1\n")
+	b.Entry("test-secret-key", "test-secret-value-2")
+	waitFor(t, &stdout, "TEST_VALUE=test-secret-value-2\n")
+
+	select {
+	case code := <-result:
+		if code != 0 {
+			t.Errorf("did not propagate exit code: %d", code)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("did not exit")
+	}
+}
+
+func Test_Exec_Restart_Kill(t *testing.T) {
+	b := bindingstest.NewRoot(t).Kubernetes().Binding("test-name-1").Entry("test-secret-key", "test-secret-value-1")
+
+	t.Setenv("TEST_HELPER_PROCESS", "1")
+	t.Setenv("TEST_IGNORE_SIGTERM", "1")
+	t.Setenv("TEST_WAIT_UNLESS", "test-secret-value-2")
+
+	var stdout, stderr syncBuffer
+	result := make(chan int, 1)
+	go func() {
+		result <- run([]string{"exec", "-root", filepath.Dir(b.Path()), "-restart", "-interval", "10ms",
+			"-grace", "100ms", "-map", "TEST_VALUE=test-name-1:test-secret-key", "--", os.Args[0],
+			"-test.run=^Test_HelperProcess$"}, &stdout, &stderr)
+	}()
+
+	waitFor(t, &stdout, "TEST_VALUE=test-secret-value-1\n")
+	b.Entry("test-secret-key", "test-secret-value-2")
+	waitFor(t, &stdout, "TEST_VALUE=test-secret-value-2\n")
+
+	if !strings.Contains(stderr.String(), "Command did not exit within 100ms, killing") {
+		t.Errorf("did not kill command: %s", stderr.String())
+	}
+
+	select {
+	case code := <-result:
+		if code != 0 {
+			t.Errorf("did not propagate exit code: %d", code)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("did not exit")
+	}
+}
+
+func Test_Exec_Restart_Signal(t *testing.T) {
+	b := bindingstest.NewRoot(t).Kubernetes().Binding("test-name-1").Entry("test-secret-key", "test-secret-value-1")
+
+	t.Setenv("TEST_HELPER_PROCESS", "1")
+	t.Setenv("TEST_IGNORE_SIGTERM", "1")
+	t.Setenv("TEST_WAIT_UNLESS", "test-secret-value-3")
+
+	var stdout, stderr syncBuffer
+	result := make(chan int, 1)
+	go func() {
+		result <- run([]string{"exec", "-root", filepath.Dir(b.Path()), "-restart", "-interval", "10ms",
+			"-map", "TEST_VALUE=test-name-1:test-secret-key", "--", os.Args[0], "-test.run=^Test_HelperProcess$"},
+			&stdout, &stderr)
+	}()
+
+	waitFor(t, &stdout, "TEST_VALUE=test-secret-value-1\n")
+	b.Entry("test-secret-key", "test-secret-value-2")
+	waitFor(t, &stderr, "Bindings changed, restarting command")
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGINT); err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	select {
+	case code := <-result:
+		if code != 128+int(syscall.SIGINT) {
+			t.Errorf("did not propagate exit code: %d", code)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("did not exit")
+	}
+
+	if strings.Contains(stdout.String(), "TEST_VALUE=test-secret-value-2\n") {
+		t.Errorf("restarted terminated command")
+	}
+}
+
+func waitFor(t *testing.T, b *syncBuffer, s string) {
+	t.Helper()
+
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); {
+		if strings.Contains(b.String(), s) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("did not write %q: %s", s, b.String())
+}
+
+type syncBuffer struct {
+	buffer bytes.Buffer
+	mutex  sync.Mutex
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.buffer.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.buffer.String()
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/nebhale/client-go/bindings"
+)
+
+type summary struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Provider string `json:"provider,omitempty"`
+}
+
+func summarize(binding bindings.Binding) summary {
+	s := summary{Name: binding.GetName()}
+	s.Type, _ = bindings.GetType(binding)
+	s.Provider, _ = bindings.GetProvider(binding)
+
+	return s
+}
+
+func list(args []string, stdout io.Writer, stderr io.Writer) int {
+	f, root := flags("list", stderr)
+	j := jsonFlag(f)
+	if err := f.Parse(args); err != nil {
+		return 2
+	}
+
+	b, ok := load(*root, stderr)
+	if !ok {
+		return 1
+	}
+
+	summaries := []summary{}
+	for _, c := range b {
+		summaries = append(summaries, summarize(c))
+	}
+
+	if *j {
+		return writeJSON(stdout, summaries)
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tTYPE\tPROVIDER")
+	for _, s := range summaries {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", s.Name, s.Type, s.Provider)
+	}
+	_ = w.Flush()
+
+	return 0
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nebhale/client-go/bindings"
+)
+
+const usage = `Usage: bindings <command> [flags] [arguments]
+
+Commands:
+  list                 list the name, type, and provider of each binding
+  show <name>          show the entries of a binding, with values redacted
+  validate             validate bindings against the Service Binding Specification
+  exec -- <command>    run a command with binding entries exported as environment variables
+
+Run 'bindings <command> -h' for the flags of a command.
+`
+
+type command func(args []string, stdout io.Writer, stderr io.Writer) int
+
+var commands = map[string]command{
+	"exec":     execCommand,
+	"list":     list,
+	"show":     show,
+	"validate": validate,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		_, _ = fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	c, ok := commands[args[0]]
+	if !ok {
+		_, _ = fmt.Fprintf(stderr, "Unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	return c(args[1:], stdout, stderr)
+}
+
+// flags creates the flags common to all commands.
+func flags(name string, stderr io.Writer) (*flag.FlagSet, *string) {
+	f := flag.NewFlagSet(name, flag.ContinueOnError)
+	f.SetOutput(stderr)
+
+	root := f.String("root", os.Getenv(bindings.ServiceBindingRoot), "bindings file system root")
+
+	return f, root
+}
+
+// jsonFlag adds a flag to write output as JSON.
+func jsonFlag(f *flag.FlagSet) *bool {
+	return f.Bool("json", false, "write output as JSON")
+}
+
+func load(root string, stderr io.Writer) ([]bindings.Binding, bool) {
+	if root == "" {
+		_, _ = fmt.Fprintf(stderr, "No bindings root: set $%s or -root\n", bindings.ServiceBindingRoot)
+		return nil, false
+	}
+
+	b, err := bindings.FromE(root)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "%v\n", err)
+		return nil, false
+	}
+
+	return b, true
+}
+
+func writeJSON(w io.Writer, v any) int {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+
+	if err := e.Encode(v); err != nil {
+		return 1
+	}
+
+	return 0
+}
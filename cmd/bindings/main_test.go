@@ -0,0 +1,201 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nebhale/client-go/bindings"
+)
+
+var testRoot = filepath.Join("..", "..", "bindings", "testdata")
+
+func Test_Run_NoCommand(t *testing.T) {
+	if execute(t) != 2 {
+		t.Errorf("did not fail")
+	}
+}
+
+func Test_Run_UnknownCommand(t *testing.T) {
+	if execute(t, "test-unknown") != 2 {
+		t.Errorf("did not fail")
+	}
+}
+
+func Test_List_NoRoot(t *testing.T) {
+	t.Setenv("SERVICE_BINDING_ROOT", "")
+
+	if execute(t, "list") != 1 {
+		t.Errorf("did not fail")
+	}
+}
+
+func Test_List_Text(t *testing.T) {
+	out, code := capture(t, "list", "-root", testRoot)
+	if code != 0 {
+		t.Fatalf("failed with %d", code)
+	}
+
+	if !strings.Contains(out, "test-name-2  test-type-2  test-provider-2") {
+		t.Errorf("did not list binding: %s", out)
+	}
+}
+
+func Test_List_JSON(t *testing.T) {
+	t.Setenv("SERVICE_BINDING_ROOT", testRoot)
+
+	out, code := capture(t, "list", "-json")
+	if code != 0 {
+		t.Fatalf("failed with %d", code)
+	}
+
+	var s []summary
+	if err := json.Unmarshal([]byte(out), &s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 3 || s[0] != (summary{Name: "test-k8s", Type: "test-type-1", Provider: "test-provider-1"}) {
+		t.Errorf("returned the wrong value: %+v", s)
+	}
+}
+
+func Test_Show_Missing(t *testing.T) {
+	if execute(t, "show", "-root", testRoot, "test-missing") != 1 {
+		t.Errorf("did not fail")
+	}
+}
+
+func Test_Show_NoName(t *testing.T) {
+	if execute(t, "show", "-root", testRoot) != 2 {
+		t.Errorf("did not fail")
+	}
+}
+
+func Test_Show_Redacted(t *testing.T) {
+	out, code := capture(t, "show", "-root", testRoot, "-json", "test-name-1")
+	if code != 0 {
+		t.Fatalf("failed with %d", code)
+	}
+
+	var d detail
+	if err := json.Unmarshal([]byte(out), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Entries["test-secret-key"] != bindings.Redacted {
+		t.Errorf("did not redact value")
+	}
+	if d.Entries["type"] != "test-type-1" {
+		t.Errorf("redacted type")
+	}
+}
+
+func Test_Show_Reveal(t *testing.T) {
+	out, code := capture(t, "show", "-root", testRoot, "-reveal", "test-name-1")
+	if code != 0 {
+		t.Fatalf("failed with %d", code)
+	}
+
+	if !strings.Contains(out, "test-secret-value") {
+		t.Errorf("did not reveal value: %s", out)
+	}
+}
+
+func Test_Validate_Invalid(t *testing.T) {
+	out, code := capture(t, "validate", "-root", testRoot, "-json")
+	if code != 1 {
+		t.Fatalf("did not fail")
+	}
+
+	var v validation
+	if err := json.Unmarshal([]byte(out), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Valid || len(v.Problems) != 1 || v.Problems[0].Entry != "additional-file" {
+		t.Errorf("returned the wrong value: %+v", v)
+	}
+}
+
+func Test_Validate_Problems(t *testing.T) {
+	root := t.TempDir()
+	for _, p := range []string{
+		filepath.Join(root, "test-name-1", "test^invalid^key"),
+		filepath.Join(root, "test-name-2", "type"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("test-value"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(root, "test-name-2", "test-directory"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	out, code := capture(t, "validate", "-root", root)
+	if code != 1 {
+		t.Fatalf("did not fail")
+	}
+
+	for _, e := range []string{
+		"test-name-1: binding does not contain a type",
+		"test-name-1/test^invalid^key: entry is not a valid secret key",
+		"test-name-2/test-directory: entry is not a regular file",
+	} {
+		if !strings.Contains(out, e) {
+			t.Errorf("did not report %q: %s", e, out)
+		}
+	}
+}
+
+func Test_Validate_Valid(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "test-name-1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "test-name-1", "type"), []byte("test-type-1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, code := capture(t, "validate", "-root", root)
+	if code != 0 {
+		t.Fatalf("failed with %d", code)
+	}
+	if out != "All bindings are valid\n" {
+		t.Errorf("returned the wrong value: %s", out)
+	}
+}
+
+func execute(t *testing.T, args ...string) int {
+	t.Helper()
+
+	_, code := capture(t, args...)
+	return code
+}
+
+func capture(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+
+	return stdout.String(), code
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/nebhale/client-go/bindings"
+)
+
+type detail struct {
+	summary
+	Entries map[string]string `json:"entries"`
+}
+
+func show(args []string, stdout io.Writer, stderr io.Writer) int {
+	f, root := flags("show", stderr)
+	j := jsonFlag(f)
+	reveal := f.Bool("reveal", false, "show values instead of redacting them")
+	if err := f.Parse(args); err != nil {
+		return 2
+	}
+
+	if f.NArg() != 1 {
+		_, _ = fmt.Fprintln(stderr, "Usage: bindings show [flags] <name>")
+		return 2
+	}
+
+	b, ok := load(*root, stderr)
+	if !ok {
+		return 1
+	}
+
+	c, ok := bindings.Find(b, f.Arg(0))
+	if !ok {
+		_, _ = fmt.Fprintf(stderr, "No binding named %q\n", f.Arg(0))
+		return 1
+	}
+
+	keys, _ := bindings.Keys(c)
+
+	d := detail{summary: summarize(c), Entries: map[string]string{}}
+	for _, k := range keys {
+		switch v, _ := bindings.Get(c, k); {
+		case *reveal, k == bindings.Type, k == bindings.Provider:
+			d.Entries[k] = v
+		default:
+			d.Entries[k] = bindings.Redacted
+		}
+	}
+
+	if *j {
+		return writeJSON(stdout, d)
+	}
+
+	_, _ = fmt.Fprintf(stdout, "Name:      %s\nType:      %s\nProvider:  %s\n\n", d.Name, d.Type, d.Provider)
+
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "KEY\tVALUE")
+	for _, k := range keys {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", k, d.Entries[k])
+	}
+	_ = w.Flush()
+
+	return 0
+}
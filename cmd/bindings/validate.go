@@ -0,0 +1,137 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nebhale/client-go/bindings"
+	"github.com/nebhale/client-go/internal"
+)
+
+type problem struct {
+	Binding string `json:"binding,omitempty"`
+	Entry   string `json:"entry,omitempty"`
+	Message string `json:"message"`
+}
+
+type validation struct {
+	Valid    bool      `json:"valid"`
+	Problems []problem `json:"problems"`
+}
+
+func validate(args []string, stdout io.Writer, stderr io.Writer) int {
+	f, root := flags("validate", stderr)
+	j := jsonFlag(f)
+	if err := f.Parse(args); err != nil {
+		return 2
+	}
+
+	if *root == "" {
+		_, _ = fmt.Fprintf(stderr, "No bindings root: set $%s or -root\n", bindings.ServiceBindingRoot)
+		return 1
+	}
+
+	problems, err := check(*root)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+
+	v := validation{Valid: len(problems) == 0, Problems: problems}
+
+	if *j {
+		if writeJSON(stdout, v) != 0 {
+			return 1
+		}
+	} else if v.Valid {
+		_, _ = fmt.Fprintln(stdout, "All bindings are valid")
+	} else {
+		for _, p := range problems {
+			var location []string
+			for _, l := range []string{p.Binding, p.Entry} {
+				if l != "" {
+					location = append(location, l)
+				}
+			}
+			_, _ = fmt.Fprintf(stdout, "%s: %s\n", strings.Join(location, "/"), p.Message)
+		}
+	}
+
+	if !v.Valid {
+		return 1
+	}
+	return 0
+}
+
+// check returns the ways in which a bindings root does not conform to the Service Binding Specification.
+func check(root string) ([]problem, error) {
+	children, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read bindings root: %w", err)
+	}
+
+	problems := []problem{}
+	for _, c := range children {
+		if !c.IsDir() {
+			problems = append(problems, problem{Entry: c.Name(), Message: "file is not a binding directory"})
+			continue
+		}
+
+		b := bindings.ConfigTreeBinding{Root: filepath.Join(root, c.Name())}
+
+		if _, err := bindings.GetType(b); err != nil {
+			problems = append(problems, problem{Binding: b.GetName(), Message: "binding does not contain a type"})
+		}
+
+		entries, err := os.ReadDir(b.Root)
+		if err != nil {
+			problems = append(problems, problem{Binding: b.GetName(), Message: err.Error()})
+			continue
+		}
+
+		for _, e := range entries {
+			n := e.Name()
+			if strings.HasPrefix(n, "..") {
+				continue
+			}
+
+			if !internal.IsValidSecretKey(n) {
+				problems = append(problems,
+					problem{Binding: b.GetName(), Entry: n, Message: "entry is not a valid secret key"})
+				continue
+			}
+
+			fi, err := os.Stat(filepath.Join(b.Root, n))
+			switch {
+			case err != nil:
+				problems = append(problems, problem{Binding: b.GetName(), Entry: n, Message: err.Error()})
+			case fi.IsDir() && strings.HasPrefix(n, "."):
+				// hidden directories are ignored
+			case !fi.Mode().IsRegular():
+				problems = append(problems,
+					problem{Binding: b.GetName(), Entry: n, Message: "entry is not a regular file"})
+			}
+		}
+	}
+
+	return problems, nil
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/nebhale/client-go/bindings"
+)
+
+// Connector is an implementation of driver.Connector that resolves a data source name from a binding on every call to
+// Connect.  When the binding reads from the filesystem, such as a ConfigTreeBinding, new connections in a sql.DB pool
+// use rotated credentials without restarting the process.  Wrapping the binding in a CacheBinding without a TTL
+// defeats this.
+//
+//	db := sql.OpenDB(database.Connector{Binding: b, Delegate: d, DSN: dsn})
+type Connector struct {
+
+	// Binding is the binding the data source name is resolved from.
+	Binding bindings.Binding
+
+	// Delegate is the driver that opens connections.  If it implements driver.DriverContext, its connectors are used.
+	Delegate driver.Driver
+
+	// DSN resolves the data source name passed to Delegate from the binding.
+	DSN func(binding bindings.Binding) (string, error)
+}
+
+// Connect resolves the data source name from the binding and opens a connection with it.
+func (c Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dsn, err := c.DSN(c.Binding)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve data source name from binding %s: %w", c.Binding.GetName(), err)
+	}
+
+	if d, ok := c.Delegate.(driver.DriverContext); ok {
+		dc, err := d.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+
+		return dc.Connect(ctx)
+	}
+
+	return c.Delegate.Open(dsn)
+}
+
+// Driver returns the delegate driver.
+func (c Connector) Driver() driver.Driver {
+	return c.Delegate
+}
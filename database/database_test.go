@@ -0,0 +1,140 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/nebhale/client-go/bindings"
+	"github.com/nebhale/client-go/bindings/bindingstest"
+	"github.com/nebhale/client-go/database"
+)
+
+type fakeDriver struct {
+	mu   sync.Mutex
+	dsns []string
+}
+
+func (f *fakeDriver) Open(dsn string) (driver.Conn, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.dsns = append(f.dsns, dsn)
+	return fakeConn{}, nil
+}
+
+type fakeContextDriver struct {
+	fakeDriver
+}
+
+func (f *fakeContextDriver) OpenConnector(dsn string) (driver.Connector, error) {
+	return fakeConnector{driver: f, dsn: dsn}, nil
+}
+
+type fakeConnector struct {
+	driver *fakeContextDriver
+	dsn    string
+}
+
+func (f fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return f.driver.Open("connector:" + f.dsn)
+}
+
+func (f fakeConnector) Driver() driver.Driver {
+	return f.driver
+}
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (fakeConn) Close() error {
+	return nil
+}
+
+func (fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not implemented")
+}
+
+func password(binding bindings.Binding) (string, error) {
+	return bindings.GetE(binding, "password")
+}
+
+func Test_Connector_Rotation(t *testing.T) {
+	b := bindingstest.NewRoot(t).Kubernetes().Binding("test-name").Entry("password", "test-password-1")
+	d := &fakeDriver{}
+
+	db := sql.OpenDB(database.Connector{Binding: bindings.ConfigTreeBinding{Root: b.Path()}, Delegate: d, DSN: password})
+	defer db.Close()
+	db.SetMaxIdleConns(0)
+
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+
+	b.Entry("password", "test-password-2")
+
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(d.dsns, []string{"test-password-1", "test-password-2"}) {
+		t.Errorf("did not re-read binding: %v", d.dsns)
+	}
+}
+
+func Test_Connector_DriverContext(t *testing.T) {
+	b := bindings.MapBinding{Name: "test-name", Content: map[string][]byte{"password": []byte("test-password")}}
+	d := &fakeContextDriver{}
+
+	c := database.Connector{Binding: b, Delegate: d, DSN: password}
+	if _, err := c.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(d.dsns, []string{"connector:test-password"}) {
+		t.Errorf("did not use driver connector: %v", d.dsns)
+	}
+}
+
+func Test_Connector_Error(t *testing.T) {
+	b := bindings.MapBinding{Name: "test-name"}
+	d := &fakeDriver{}
+
+	c := database.Connector{Binding: b, Delegate: d, DSN: password}
+	if _, err := c.Connect(context.Background()); !errors.Is(err, bindings.ErrNotFound) {
+		t.Errorf("does not identify missing entry: %v", err)
+	}
+	if len(d.dsns) != 0 {
+		t.Errorf("opened a connection")
+	}
+}
+
+func Test_Connector_Driver(t *testing.T) {
+	d := &fakeDriver{}
+	if (database.Connector{Delegate: d}).Driver() != d {
+		t.Errorf("returned the wrong value")
+	}
+}
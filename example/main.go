@@ -23,6 +23,7 @@ import (
 
 	"github.com/jackc/pgx/v4"
 	"github.com/nebhale/client-go/bindings"
+	"github.com/nebhale/client-go/postgresql"
 )
 
 func main() {
@@ -33,13 +34,19 @@ func main() {
 		os.Exit(1)
 	}
 
-	u, ok := bindings.Get(b[0], "url")
-	if !ok {
-		_, _ = fmt.Fprintln(os.Stderr, "No URL in binding")
+	c, err := postgresql.Load(b[0])
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Invalid PostgreSQL binding: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := c.ConnConfig()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Invalid PostgreSQL configuration: %v\n", err)
 		os.Exit(1)
 	}
 
-	conn, err := pgx.Connect(context.Background(), u)
+	conn, err := pgx.ConnectConfig(context.Background(), cfg)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Unable to connect to database: %v\n", err)
 		os.Exit(1)
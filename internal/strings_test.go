@@ -0,0 +1,33 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/nebhale/client-go/internal"
+)
+
+func Test_SetIfEmpty(t *testing.T) {
+	s := ""
+	internal.SetIfEmpty(&s, "test-value-1")
+	internal.SetIfEmpty(&s, "test-value-2")
+
+	if s != "test-value-1" {
+		t.Errorf("returned the wrong value: %s", s)
+	}
+}
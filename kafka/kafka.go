@@ -0,0 +1,337 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kafka
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/nebhale/client-go/bindings"
+	"github.com/nebhale/client-go/internal"
+)
+
+// Type is the type of Kafka bindings.
+const Type = "kafka"
+
+// DefaultPort is the port used for brokers that do not specify one.
+const DefaultPort = "9092"
+
+// Security protocols, as named by the security.protocol Kafka client property.
+const (
+	Plaintext     = "PLAINTEXT"
+	SSL           = "SSL"
+	SASLPlaintext = "SASL_PLAINTEXT"
+	SASLSSL       = "SASL_SSL"
+)
+
+// SASL mechanisms, as named by the sasl.mechanism Kafka client property.
+const (
+	Plain       = "PLAIN"
+	ScramSHA256 = "SCRAM-SHA-256"
+	ScramSHA512 = "SCRAM-SHA-512"
+	OAuthBearer = "OAUTHBEARER"
+)
+
+// Config is the client configuration described by a Kafka binding, independent of any particular client library.
+type Config struct {
+
+	// BootstrapServers are the addresses of the brokers used to discover the cluster, read from a comma separated
+	// list.
+	BootstrapServers []string `binding:"bootstrap-servers"`
+
+	// SecurityProtocol is the protocol used to communicate with brokers: PLAINTEXT, SSL, SASL_PLAINTEXT, or SASL_SSL.
+	SecurityProtocol string `binding:"security.protocol"`
+
+	// SASL is the SASL configuration, used if the SecurityProtocol is SASL_PLAINTEXT or SASL_SSL.
+	SASL SASLConfig `binding:"sasl"`
+
+	// TLS is the TLS configuration, set if the SecurityProtocol is SSL or SASL_SSL.
+	TLS *tls.Config `binding:"-"`
+}
+
+// SASLConfig is the SASL configuration of a Kafka binding.
+type SASLConfig struct {
+
+	// Mechanism is the SASL mechanism: PLAIN, SCRAM-SHA-256, SCRAM-SHA-512, or OAUTHBEARER.
+	Mechanism string `binding:"mechanism"`
+
+	// JAASConfig is the JAAS login configuration that the other fields are read from if not set by their own entries.
+	JAASConfig string `binding:"jaas.config"`
+
+	// Username is the user to authenticate as with PLAIN and SCRAM.
+	Username string `binding:"-"`
+
+	// Password is the password of the user with PLAIN and SCRAM.
+	Password string `binding:"-"`
+
+	// OAuthBearer is the OAuth configuration used with OAUTHBEARER.
+	OAuthBearer OAuthBearerConfig `binding:"oauthbearer"`
+
+	// Options are the options of the JAAS login configuration.
+	Options map[string]string `binding:"-"`
+}
+
+// OAuthBearerConfig is the OAuth client credentials configuration of a Kafka binding.
+type OAuthBearerConfig struct {
+
+	// TokenEndpointURL is the URL of the OAuth token endpoint.
+	TokenEndpointURL string `binding:"token.endpoint.url"`
+
+	// ClientID is the OAuth client id, read from the clientId JAAS option.
+	ClientID string `binding:"-"`
+
+	// ClientSecret is the OAuth client secret, read from the clientSecret JAAS option.
+	ClientSecret string `binding:"-"`
+
+	// Scope is the OAuth scope, read from the scope JAAS option.
+	Scope string `binding:"-"`
+}
+
+// Load reads the client configuration from a binding of type kafka.  SASL credentials are read from the username and
+// password entries, or else from the options of the sasl.jaas.config entry.  If sasl.mechanism is not set, it is
+// inferred from the JAAS login module, or is PLAIN if there is a username.  If security.protocol is not set, it is
+// inferred from whether SASL is configured and whether the binding contains certificate entries.  TLS is configured
+// from certificate entries if present, and otherwise verifies brokers against the system roots.
+func Load(binding bindings.Binding) (*Config, error) {
+	t, err := bindings.GetType(binding)
+	if err != nil {
+		return nil, fmt.Errorf("binding %s: %w", binding.GetName(), err)
+	}
+	if !strings.EqualFold(t, Type) {
+		return nil, fmt.Errorf("binding %s is of type %s, not %s", binding.GetName(), t, Type)
+	}
+
+	var c Config
+	if err := bindings.Unmarshal(binding, &c); err != nil {
+		return nil, err
+	}
+
+	if len(c.BootstrapServers) == 0 {
+		return nil, fmt.Errorf("binding %s is missing bootstrap-servers", binding.GetName())
+	}
+	for i, s := range c.BootstrapServers {
+		if _, _, err := net.SplitHostPort(s); err != nil {
+			c.BootstrapServers[i] = net.JoinHostPort(s, DefaultPort)
+		}
+	}
+
+	if err := c.SASL.load(binding); err != nil {
+		return nil, fmt.Errorf("binding %s: %w", binding.GetName(), err)
+	}
+
+	certificates := bindings.HasTLS(binding)
+
+	c.SecurityProtocol = strings.ToUpper(c.SecurityProtocol)
+	if c.SecurityProtocol == "" {
+		switch {
+		case c.SASL.Mechanism != "" && certificates:
+			c.SecurityProtocol = SASLSSL
+		case c.SASL.Mechanism != "":
+			c.SecurityProtocol = SASLPlaintext
+		case certificates:
+			c.SecurityProtocol = SSL
+		default:
+			c.SecurityProtocol = Plaintext
+		}
+	}
+
+	switch c.SecurityProtocol {
+	case Plaintext, SSL:
+	case SASLPlaintext, SASLSSL:
+		if err := c.SASL.validate(); err != nil {
+			return nil, fmt.Errorf("binding %s: %w", binding.GetName(), err)
+		}
+	default:
+		return nil, fmt.Errorf("binding %s: unsupported security.protocol %q", binding.GetName(), c.SecurityProtocol)
+	}
+
+	if c.SecurityProtocol == SSL || c.SecurityProtocol == SASLSSL {
+		if certificates {
+			if c.TLS, err = bindings.TLSConfig(binding); err != nil {
+				return nil, err
+			}
+		} else {
+			c.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+	}
+
+	return &c, nil
+}
+
+// load fills the credentials from the username and password entries and from the JAAS login configuration, and infers
+// the mechanism if it is not set.
+func (s *SASLConfig) load(binding bindings.Binding) error {
+	s.Username, _ = bindings.Get(binding, "username")
+	s.Password, _ = bindings.Get(binding, "password")
+
+	var module string
+	if s.JAASConfig != "" {
+		var err error
+		if module, s.Options, err = parseJAAS(s.JAASConfig); err != nil {
+			return fmt.Errorf("invalid sasl.jaas.config: %w", err)
+		}
+
+		internal.SetIfEmpty(&s.Username, s.Options["username"])
+		internal.SetIfEmpty(&s.Password, s.Options["password"])
+		internal.SetIfEmpty(&s.OAuthBearer.ClientID, s.Options["clientId"])
+		internal.SetIfEmpty(&s.OAuthBearer.ClientSecret, s.Options["clientSecret"])
+		internal.SetIfEmpty(&s.OAuthBearer.Scope, s.Options["scope"])
+	}
+
+	s.Mechanism = strings.ToUpper(s.Mechanism)
+	if s.Mechanism != "" {
+		return nil
+	}
+
+	switch {
+	case strings.HasSuffix(module, ".PlainLoginModule"):
+		s.Mechanism = Plain
+	case strings.HasSuffix(module, ".OAuthBearerLoginModule"):
+		s.Mechanism = OAuthBearer
+	case strings.HasSuffix(module, ".ScramLoginModule"):
+		return fmt.Errorf("sasl.mechanism must be set to %s or %s for %s", ScramSHA256, ScramSHA512, module)
+	case s.Username != "":
+		s.Mechanism = Plain
+	}
+
+	return nil
+}
+
+// validate ensures that the settings the mechanism requires are present.
+func (s *SASLConfig) validate() error {
+	var missing []string
+
+	switch s.Mechanism {
+	case "":
+		return fmt.Errorf("SASL requires sasl.mechanism")
+	case Plain, ScramSHA256, ScramSHA512:
+		if s.Username == "" {
+			missing = append(missing, "username")
+		}
+		if s.Password == "" {
+			missing = append(missing, "password")
+		}
+	case OAuthBearer:
+		if s.OAuthBearer.TokenEndpointURL == "" {
+			missing = append(missing, "sasl.oauthbearer.token.endpoint.url")
+		}
+		if s.OAuthBearer.ClientID == "" {
+			missing = append(missing, "clientId")
+		}
+		if s.OAuthBearer.ClientSecret == "" {
+			missing = append(missing, "clientSecret")
+		}
+	default:
+		return fmt.Errorf("unsupported sasl.mechanism %q", s.Mechanism)
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("sasl.mechanism %s requires %s", s.Mechanism, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// parseJAAS parses a single JAAS login module entry of the form:
+//
+//	<module> <flag> [<option>=<value> ...];
+//
+// Whitespace is allowed around the "=".  Values may be quoted with double quotes, in which case a backslash escapes the
+// following character.
+func parseJAAS(config string) (string, map[string]string, error) {
+	s := strings.TrimSpace(config)
+	s = strings.TrimSpace(strings.TrimSuffix(s, ";"))
+
+	tokens, err := tokenize(s)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(tokens) < 2 || tokens[0].equals || tokens[1].equals {
+		return "", nil, fmt.Errorf("missing login module or control flag")
+	}
+
+	switch strings.ToLower(tokens[1].value) {
+	case "required", "requisite", "sufficient", "optional":
+	default:
+		return "", nil, fmt.Errorf("invalid control flag %q", tokens[1].value)
+	}
+
+	options := make(map[string]string)
+	for t := tokens[2:]; len(t) > 0; t = t[min(3, len(t)):] {
+		if len(t) < 3 || t[0].equals || t[0].value == "" || !t[1].equals || t[2].equals {
+			return "", nil, fmt.Errorf("invalid option %q", t[0].value)
+		}
+		options[t[0].value] = t[2].value
+	}
+
+	return tokens[0].value, options, nil
+}
+
+// token is an element of a JAAS entry: either a word, with quotes and escapes removed, or an unquoted "=".
+type token struct {
+	value  string
+	equals bool
+}
+
+// tokenize splits a JAAS entry on whitespace and "=" outside of quotes, removing quotes and escapes.
+func tokenize(s string) ([]token, error) {
+	var (
+		tokens  []token
+		current strings.Builder
+		started bool
+		quoted  bool
+		escaped bool
+	)
+
+	flush := func() {
+		if started {
+			tokens = append(tokens, token{value: current.String()})
+			current.Reset()
+			started = false
+		}
+	}
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+			started = true
+		case !quoted && r == '=':
+			flush()
+			tokens = append(tokens, token{equals: true})
+		case !quoted && (r == ' ' || r == '\t' || r == '\n' || r == '\r'):
+			flush()
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+
+	if quoted || escaped {
+		return nil, fmt.Errorf("unterminated quoted value")
+	}
+	flush()
+
+	return tokens, nil
+}
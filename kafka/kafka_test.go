@@ -0,0 +1,225 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kafka_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nebhale/client-go/bindings"
+	"github.com/nebhale/client-go/bindings/bindingstest"
+	"github.com/nebhale/client-go/kafka"
+)
+
+func Test_Load_Plaintext(t *testing.T) {
+	c, err := kafka.Load(bindings.MapBinding{
+		Name: "test-name",
+		Content: map[string][]byte{
+			"type":              []byte("kafka"),
+			"bootstrap-servers": []byte("test-host-1:9093, test-host-2"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	if !reflect.DeepEqual(c.BootstrapServers, []string{"test-host-1:9093", "test-host-2:9092"}) {
+		t.Errorf("returned the wrong brokers: %v", c.BootstrapServers)
+	}
+	if c.SecurityProtocol != kafka.Plaintext || c.SASL.Mechanism != "" || c.TLS != nil {
+		t.Errorf("returned the wrong values: %+v", c)
+	}
+}
+
+func Test_Load_Plain(t *testing.T) {
+	c, err := kafka.Load(bindings.MapBinding{
+		Name: "test-name",
+		Content: map[string][]byte{
+			"type":              []byte("kafka"),
+			"bootstrap-servers": []byte("test-host:9092"),
+			"security.protocol": []byte("sasl_ssl"),
+			"username":          []byte("test-username"),
+			"password":          []byte("test-password"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	if c.SecurityProtocol != kafka.SASLSSL || c.SASL.Mechanism != kafka.Plain || c.SASL.Username != "test-username" ||
+		c.SASL.Password != "test-password" {
+		t.Errorf("returned the wrong values: %+v", c)
+	}
+	if c.TLS == nil {
+		t.Errorf("did not configure TLS")
+	}
+}
+
+func Test_Load_JAAS_Plain(t *testing.T) {
+	c, err := kafka.Load(bindings.MapBinding{
+		Name: "test-name",
+		Content: map[string][]byte{
+			"type":              []byte("kafka"),
+			"bootstrap-servers": []byte("test-host:9092"),
+			"sasl.jaas.config":  []byte(`org.apache.kafka.common.security.plain.PlainLoginModule required username="test-username" password="test \"pass\\word";`),
+		},
+	})
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	if c.SecurityProtocol != kafka.SASLPlaintext || c.SASL.Mechanism != kafka.Plain || c.SASL.Username != "test-username" ||
+		c.SASL.Password != `test "pass\word` {
+		t.Errorf("returned the wrong values: %+v", c.SASL)
+	}
+}
+
+func Test_Load_JAAS_Whitespace(t *testing.T) {
+	b := bindings.MapBinding{
+		Name: "test-name",
+		Content: map[string][]byte{
+			"type":              []byte("kafka"),
+			"bootstrap-servers": []byte("test-host:9092"),
+			"sasl.jaas.config": []byte("org.apache.kafka.common.security.plain.PlainLoginModule required\n" +
+				"  username = \"test-username\"\n  password= test-password ;"),
+		},
+	}
+
+	c, err := kafka.Load(b)
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	if c.SASL.Username != "test-username" || c.SASL.Password != "test-password" {
+		t.Errorf("returned the wrong values: %+v", c.SASL)
+	}
+
+	b.Content["sasl.jaas.config"] = []byte(`test.Module required username = "test-username" password =;`)
+	if _, err := kafka.Load(b); err == nil || !strings.Contains(err.Error(), `invalid option "password"`) {
+		t.Errorf("does not identify option without value: %v", err)
+	}
+}
+
+func Test_Load_JAAS_Scram(t *testing.T) {
+	b := bindings.MapBinding{
+		Name: "test-name",
+		Content: map[string][]byte{
+			"type":              []byte("kafka"),
+			"bootstrap-servers": []byte("test-host:9092"),
+			"sasl.jaas.config":  []byte(`org.apache.kafka.common.security.scram.ScramLoginModule required username="test-username" password="test-password";`),
+			"password":          []byte("test-password-2"),
+		},
+	}
+
+	if _, err := kafka.Load(b); err == nil || !strings.Contains(err.Error(), "sasl.mechanism") {
+		t.Errorf("does not identify missing mechanism: %v", err)
+	}
+
+	b.Content["sasl.mechanism"] = []byte("scram-sha-512")
+	c, err := kafka.Load(b)
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	if c.SASL.Mechanism != kafka.ScramSHA512 || c.SASL.Username != "test-username" {
+		t.Errorf("returned the wrong values: %+v", c.SASL)
+	}
+	if c.SASL.Password != "test-password-2" {
+		t.Errorf("did not give entries precedence")
+	}
+}
+
+func Test_Load_OAuthBearer(t *testing.T) {
+	c, err := kafka.Load(bindings.MapBinding{
+		Name: "test-name",
+		Content: map[string][]byte{
+			"type":                                []byte("kafka"),
+			"bootstrap-servers":                   []byte("test-host:9092"),
+			"sasl.mechanism":                      []byte("OAUTHBEARER"),
+			"sasl.oauthbearer.token.endpoint.url": []byte("https://test-host/token"),
+			"sasl.jaas.config":                    []byte(`org.apache.kafka.common.security.oauthbearer.OAuthBearerLoginModule required clientId="test-client" clientSecret="test-secret" scope="test-scope";`),
+		},
+	})
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	o := c.SASL.OAuthBearer
+	if o.TokenEndpointURL != "https://test-host/token" || o.ClientID != "test-client" || o.ClientSecret != "test-secret" ||
+		o.Scope != "test-scope" {
+		t.Errorf("returned the wrong values: %+v", o)
+	}
+	if c.SASL.Options["scope"] != "test-scope" {
+		t.Errorf("returned the wrong options: %v", c.SASL.Options)
+	}
+}
+
+func Test_Load_Certificates(t *testing.T) {
+	cert, key := bindingstest.Certificate(t)
+
+	c, err := kafka.Load(bindings.MapBinding{
+		Name: "test-name",
+		Content: map[string][]byte{
+			"type":              []byte("kafka"),
+			"bootstrap-servers": []byte("test-host:9092"),
+			"ca.crt":            cert,
+			"tls.crt":           cert,
+			"tls.key":           key,
+		},
+	})
+	if err != nil {
+		t.Fatalf("returned an error: %v", err)
+	}
+
+	if c.SecurityProtocol != kafka.SSL {
+		t.Errorf("returned the wrong protocol: %s", c.SecurityProtocol)
+	}
+	if c.TLS == nil || c.TLS.RootCAs == nil || c.TLS.GetClientCertificate == nil {
+		t.Errorf("did not configure TLS")
+	}
+}
+
+func Test_Load_Invalid(t *testing.T) {
+	for expected, content := range map[string]map[string]string{
+		"missing bootstrap-servers": {},
+		"requires password":         {"bootstrap-servers": "test-host", "username": "test-username"},
+		"requires sasl.oauthbearer.token.endpoint.url, clientId, clientSecret": {
+			"bootstrap-servers": "test-host", "sasl.mechanism": "oauthbearer",
+		},
+		"unsupported sasl.mechanism":    {"bootstrap-servers": "test-host", "sasl.mechanism": "GSSAPI", "security.protocol": "SASL_SSL"},
+		"SASL requires sasl.mechanism":  {"bootstrap-servers": "test-host", "security.protocol": "SASL_SSL"},
+		"unsupported security.protocol": {"bootstrap-servers": "test-host", "security.protocol": "TLS"},
+		"invalid sasl.jaas.config":      {"bootstrap-servers": "test-host", "sasl.jaas.config": `test.Module required username="test`},
+	} {
+		b := bindings.MapBinding{Name: "test-name", Content: map[string][]byte{"type": []byte("kafka")}}
+		for k, v := range content {
+			b.Content[k] = []byte(v)
+		}
+
+		if _, err := kafka.Load(b); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("does not identify %q: %v", expected, err)
+		}
+	}
+
+	if _, err := kafka.Load(bindings.MapBinding{
+		Name:    "test-name",
+		Content: map[string][]byte{"type": []byte("redis")},
+	}); err == nil {
+		t.Errorf("does not identify invalid type")
+	}
+}
@@ -0,0 +1,382 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/nebhale/client-go/bindings"
+	"github.com/nebhale/client-go/internal"
+)
+
+// Type is the type of MySQL bindings.
+const Type = "mysql"
+
+// MariaDBType is the type of MariaDB bindings.
+const MariaDBType = "mariadb"
+
+// DefaultPort is the port used if a binding does not specify one.
+const DefaultPort = "3306"
+
+// Config is the connection configuration described by a MySQL or MariaDB binding.
+type Config struct {
+
+	// Host is the host of the server.
+	Host string `binding:"host"`
+
+	// Port is the port of the server.
+	Port string `binding:"port"`
+
+	// Database is the name of the database.
+	Database string `binding:"database"`
+
+	// Username is the user to connect as.
+	Username string `binding:"username"`
+
+	// Password is the password of the user.
+	Password string `binding:"password"`
+
+	// SSLMode is the MySQL ssl-mode to connect with: DISABLED, PREFERRED, REQUIRED, VERIFY_CA, or VERIFY_IDENTITY.
+	// If empty, TLS is used only if the binding contains certificate entries, in which case VERIFY_IDENTITY is
+	// assumed.
+	SSLMode string `binding:"ssl-mode"`
+
+	// Params are additional go-sql-driver connection parameters.
+	Params map[string]string `binding:"-"`
+
+	// TLS is the TLS configuration built from the certificate entries of the binding, or nil if there are none.
+	TLS *tls.Config `binding:"-"`
+
+	name        string
+	fingerprint string
+}
+
+// Load reads the connection configuration from a binding of type mysql or mariadb.  The configuration may be
+// described by a url entry (mysql:// or mariadb://), by a jdbc-url entry (jdbc:mysql:// or jdbc:mariadb://), by the
+// host, port, database, username, password, and ssl-mode entries, or by a combination, in which case the discrete
+// entries take precedence over url, and url over jdbc-url.  If the binding contains ca.crt, tls.crt, or tls.key
+// entries, they are used to configure TLS.
+func Load(binding bindings.Binding) (*Config, error) {
+	t, err := bindings.GetType(binding)
+	if err != nil {
+		return nil, fmt.Errorf("binding %s: %w", binding.GetName(), err)
+	}
+	if !strings.EqualFold(t, Type) && !strings.EqualFold(t, MariaDBType) {
+		return nil, fmt.Errorf("binding %s is of type %s, not %s or %s", binding.GetName(), t, Type, MariaDBType)
+	}
+
+	c := Config{name: binding.GetName()}
+	if err := bindings.Unmarshal(binding, &c); err != nil {
+		return nil, err
+	}
+
+	if u, ok := bindings.Get(binding, "url"); ok {
+		if err := c.merge(u, false); err != nil {
+			return nil, fmt.Errorf("binding %s: invalid url: %w", binding.GetName(), err)
+		}
+	}
+
+	if u, ok := bindings.Get(binding, "jdbc-url"); ok {
+		if err := c.merge(u, true); err != nil {
+			return nil, fmt.Errorf("binding %s: invalid jdbc-url: %w", binding.GetName(), err)
+		}
+	}
+
+	if c.Host == "" {
+		return nil, fmt.Errorf("binding %s must contain either a host, a url, or a jdbc-url", binding.GetName())
+	}
+
+	if c.SSLMode, err = normalizeSSLMode(c.SSLMode); err != nil {
+		return nil, fmt.Errorf("binding %s: %w", binding.GetName(), err)
+	}
+
+	if bindings.HasTLS(binding) {
+		if c.TLS, err = bindings.TLSConfig(binding); err != nil {
+			return nil, err
+		}
+		c.fingerprint = fingerprint(binding)
+	}
+
+	return &c, nil
+}
+
+// DriverConfig returns the configuration as a go-sql-driver *mysql.Config.  TLS is configured from the certificate
+// entries of the binding according to the ssl-mode.
+func (c *Config) DriverConfig() (*mysql.Config, error) {
+	cfg := mysql.NewConfig()
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(c.Host, c.port())
+	cfg.DBName = c.Database
+	cfg.User = c.Username
+	cfg.Passwd = c.Password
+
+	for k, v := range c.Params {
+		if cfg.Params == nil {
+			cfg.Params = make(map[string]string)
+		}
+		cfg.Params[k] = v
+	}
+
+	mode := c.SSLMode
+	if mode == "" && c.TLS != nil {
+		mode = "VERIFY_IDENTITY"
+	}
+
+	base := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.TLS != nil {
+		base = c.TLS.Clone()
+	}
+
+	switch mode {
+	case "":
+	case "DISABLED":
+		cfg.TLSConfig = "false"
+	case "PREFERRED":
+		base.InsecureSkipVerify = true
+		cfg.TLS = base
+		cfg.AllowFallbackToPlaintext = true
+	case "REQUIRED":
+		if base.RootCAs == nil {
+			base.InsecureSkipVerify = true
+			cfg.TLS = base
+			break
+		}
+
+		// as with the mysql client, the server's certificate chain is verified if certificate authorities are present
+		fallthrough
+	case "VERIFY_CA":
+		if base.RootCAs == nil {
+			return nil, fmt.Errorf("ssl-mode %s requires a %s entry", mode, bindings.CACertificate)
+		}
+		base.InsecureSkipVerify = true
+		base.VerifyPeerCertificate = verifyChain(base.RootCAs)
+		cfg.TLS = base
+	case "VERIFY_IDENTITY":
+		if base.ServerName == "" {
+			base.ServerName = c.Host
+		}
+		cfg.TLS = base
+	}
+
+	return cfg, nil
+}
+
+// DSN returns the configuration as a go-sql-driver data source name.  If TLS is configured, the TLS configuration is
+// registered with mysql.RegisterTLSConfig and referenced by the tls parameter.  The registration is global to the
+// process and is never removed.  It is named for the binding, its TLS entries, and the host and ssl-mode, so that
+// configurations loaded from different bindings with the same name do not replace each other, while repeated calls for
+// an unchanged binding reuse the same registration.
+func (c *Config) DSN() (string, error) {
+	cfg, err := c.DriverConfig()
+	if err != nil {
+		return "", err
+	}
+
+	if cfg.TLS != nil {
+		// a Config that was not loaded from a binding is identified by its TLS configuration
+		f := c.fingerprint
+		if f == "" {
+			f = fmt.Sprintf("%p", c.TLS)
+		}
+
+		h := sha256.Sum256([]byte(strings.Join([]string{f, c.Host, c.SSLMode}, "\x00")))
+		key := fmt.Sprintf("binding-%s-%x", c.name, h[:8])
+		if err := mysql.RegisterTLSConfig(key, cfg.TLS); err != nil {
+			return "", err
+		}
+
+		cfg.TLS, cfg.TLSConfig = nil, key
+	}
+
+	return cfg.FormatDSN(), nil
+}
+
+// Connector returns a driver.Connector for use with sql.OpenDB.  The configuration, including TLS, is loaded from the
+// binding on every call to Connect so that new connections in the pool use rotated credentials.
+func Connector(binding bindings.Binding) driver.Connector {
+	return connector{binding: binding}
+}
+
+type connector struct {
+	binding bindings.Binding
+}
+
+func (c connector) Connect(ctx context.Context) (driver.Conn, error) {
+	cfg, err := Load(c.binding)
+	if err != nil {
+		return nil, err
+	}
+
+	dc, err := cfg.DriverConfig()
+	if err != nil {
+		return nil, fmt.Errorf("binding %s: %w", c.binding.GetName(), err)
+	}
+
+	conn, err := mysql.NewConnector(dc)
+	if err != nil {
+		return nil, fmt.Errorf("binding %s: %w", c.binding.GetName(), err)
+	}
+
+	return conn.Connect(ctx)
+}
+
+func (c connector) Driver() driver.Driver {
+	return &mysql.MySQLDriver{}
+}
+
+func (c *Config) port() string {
+	if c.Port == "" {
+		return DefaultPort
+	}
+
+	return c.Port
+}
+
+// merge fills the fields that are not already set from a mysql:// or mariadb:// URL, or from a JDBC URL.  Parameters
+// of a JDBC URL are specific to JDBC drivers, so only the credentials and TLS parameters are used.
+func (c *Config) merge(raw string, jdbc bool) error {
+	if jdbc {
+		if !strings.HasPrefix(raw, "jdbc:") {
+			return fmt.Errorf("not a JDBC URL")
+		}
+		raw = strings.TrimPrefix(raw, "jdbc:")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != Type && u.Scheme != MariaDBType {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+
+	if host, port, err := net.SplitHostPort(u.Host); err == nil {
+		internal.SetIfEmpty(&c.Host, host)
+		internal.SetIfEmpty(&c.Port, port)
+	} else {
+		internal.SetIfEmpty(&c.Host, u.Host)
+	}
+	internal.SetIfEmpty(&c.Database, strings.TrimPrefix(u.Path, "/"))
+
+	if u.User != nil {
+		internal.SetIfEmpty(&c.Username, u.User.Username())
+		if p, ok := u.User.Password(); ok {
+			internal.SetIfEmpty(&c.Password, p)
+		}
+	}
+
+	var useSSL string
+	for k, v := range u.Query() {
+		switch strings.ToLower(k) {
+		case "user":
+			internal.SetIfEmpty(&c.Username, v[0])
+		case "password":
+			internal.SetIfEmpty(&c.Password, v[0])
+		case "ssl-mode", "sslmode":
+			internal.SetIfEmpty(&c.SSLMode, v[0])
+		case "usessl":
+			useSSL = v[0]
+		default:
+			if jdbc {
+				continue
+			}
+
+			if c.Params == nil {
+				c.Params = make(map[string]string)
+			}
+			c.Params[k] = v[0]
+		}
+	}
+
+	if strings.EqualFold(useSSL, "false") {
+		internal.SetIfEmpty(&c.SSLMode, "DISABLED")
+	} else if strings.EqualFold(useSSL, "true") {
+		internal.SetIfEmpty(&c.SSLMode, "REQUIRED")
+	}
+
+	return nil
+}
+
+// normalizeSSLMode converts MySQL and MariaDB ssl-mode values to the MySQL names.
+func normalizeSSLMode(mode string) (string, error) {
+	switch strings.ToUpper(strings.ReplaceAll(mode, "-", "_")) {
+	case "":
+		return "", nil
+	case "DISABLED", "DISABLE":
+		return "DISABLED", nil
+	case "PREFERRED":
+		return "PREFERRED", nil
+	case "REQUIRED", "TRUST":
+		return "REQUIRED", nil
+	case "VERIFY_CA":
+		return "VERIFY_CA", nil
+	case "VERIFY_IDENTITY", "VERIFY_FULL":
+		return "VERIFY_IDENTITY", nil
+	default:
+		return "", fmt.Errorf("unsupported ssl-mode %q", mode)
+	}
+}
+
+// verifyChain verifies the server's certificate chain against a set of certificate authorities without verifying its
+// host name.
+func verifyChain(roots *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(raw [][]byte, _ [][]*x509.Certificate) error {
+		if len(raw) == 0 {
+			return errors.New("server presented no certificates")
+		}
+
+		certs := make([]*x509.Certificate, len(raw))
+		for i, r := range raw {
+			c, err := x509.ParseCertificate(r)
+			if err != nil {
+				return err
+			}
+			certs[i] = c
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, c := range certs[1:] {
+			intermediates.AddCert(c)
+		}
+
+		_, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+		return err
+	}
+}
+
+// fingerprint returns a digest of the TLS entries of a binding.
+func fingerprint(binding bindings.Binding) string {
+	h := sha256.New()
+	for _, k := range []string{bindings.CACertificate, bindings.Certificate, bindings.PrivateKey, bindings.ServerName,
+		bindings.Insecure} {
+		v, _ := binding.GetAsBytes(k)
+		_, _ = fmt.Fprintf(h, "%s:%d:%s\x00", k, len(v), v)
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
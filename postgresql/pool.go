@@ -0,0 +1,231 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgresql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+	"weak"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nebhale/client-go/bindings"
+)
+
+// Reloader installs hooks on a *pgxpool.Config that load the configuration from a postgresql binding before each new
+// connection, so that rotated credentials and TLS material are used without recreating the pool.
+//
+//	cfg, _ := c.PoolConfig()
+//	(&postgresql.Reloader{Binding: b, Recycle: true}).Install(cfg)
+//	pool, _ := pgxpool.ConnectConfig(ctx, cfg)
+type Reloader struct {
+
+	// Binding is the postgresql binding that the configuration is loaded from.
+	Binding bindings.Binding
+
+	// Recycle closes connections made with a previous configuration once the binding changes.  Idle connections are
+	// closed when they are next acquired, and connections in use are closed when they are released.
+	Recycle bool
+
+	// Interval is the minimum time between checks of the binding for changes when recycling.  If zero,
+	// bindings.DefaultWatchInterval is used.
+	Interval time.Duration
+
+	mu          sync.Mutex
+	fingerprint string
+	generation  uint64
+	checked     time.Time
+	conns       map[weak.Pointer[pgconn.PgConn]]uint64
+}
+
+// Install adds the hooks to a pool configuration.  Any hooks already configured are called after the Reloader's.
+func (r *Reloader) Install(cfg *pgxpool.Config) {
+	beforeConnect := cfg.BeforeConnect
+	cfg.BeforeConnect = func(ctx context.Context, cc *pgx.ConnConfig) error {
+		if err := r.reload(cc); err != nil {
+			return err
+		}
+
+		if beforeConnect != nil {
+			return beforeConnect(ctx, cc)
+		}
+		return nil
+	}
+
+	if !r.Recycle {
+		return
+	}
+
+	beforeAcquire := cfg.BeforeAcquire
+	cfg.BeforeAcquire = func(ctx context.Context, conn *pgx.Conn) bool {
+		if !r.current(conn.PgConn()) {
+			return false
+		}
+
+		return beforeAcquire == nil || beforeAcquire(ctx, conn)
+	}
+
+	afterRelease := cfg.AfterRelease
+	cfg.AfterRelease = func(conn *pgx.Conn) bool {
+		if !r.current(conn.PgConn()) {
+			return false
+		}
+
+		return afterRelease == nil || afterRelease(conn)
+	}
+}
+
+// reload loads the configuration from the binding into the configuration of a new connection.  When recycling, the
+// connection records which generation of the binding it was made with once it is established.
+func (r *Reloader) reload(cc *pgx.ConnConfig) error {
+	c, err := Load(r.Binding)
+	if err != nil {
+		return err
+	}
+
+	fresh, err := c.ConnConfig()
+	if err != nil {
+		return err
+	}
+
+	cc.Host = fresh.Host
+	cc.Port = fresh.Port
+	cc.Database = fresh.Database
+	cc.User = fresh.User
+	cc.Password = fresh.Password
+	cc.TLSConfig = fresh.TLSConfig
+	cc.Fallbacks = fresh.Fallbacks
+	cc.RuntimeParams = fresh.RuntimeParams
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	g := r.update(fingerprint(r.Binding, c))
+	if r.Recycle {
+		afterConnect := cc.AfterConnect
+		cc.AfterConnect = func(ctx context.Context, pc *pgconn.PgConn) error {
+			if afterConnect != nil {
+				if err := afterConnect(ctx, pc); err != nil {
+					return err
+				}
+			}
+
+			r.track(pc, g)
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// current returns whether a connection was made with the current generation of the binding.  The binding is re-read
+// at most once per Interval.  If it cannot be loaded, existing connections are kept.  Connections that were not made
+// by the Reloader are always current.
+func (r *Reloader) current(pc *pgconn.PgConn) bool {
+	if pc == nil {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	interval := r.Interval
+	if interval == 0 {
+		interval = bindings.DefaultWatchInterval
+	}
+
+	if time.Since(r.checked) >= interval {
+		if c, err := Load(r.Binding); err == nil {
+			r.update(fingerprint(r.Binding, c))
+		}
+	}
+
+	g, ok := r.conns[weak.Make(pc)]
+	return !ok || g == r.generation
+}
+
+// update records the fingerprint of the binding, advancing the generation if it has changed, and returns the current
+// generation.  The caller must hold the lock.
+func (r *Reloader) update(fingerprint string) uint64 {
+	r.checked = time.Now()
+
+	if fingerprint != r.fingerprint {
+		r.fingerprint = fingerprint
+		r.generation++
+	}
+
+	return r.generation
+}
+
+// track records the generation a connection was made with until the connection is garbage collected.
+func (r *Reloader) track(pc *pgconn.PgConn, generation uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.conns == nil {
+		r.conns = make(map[weak.Pointer[pgconn.PgConn]]uint64)
+	}
+
+	p := weak.Make(pc)
+	runtime.AddCleanup(pc, func(p weak.Pointer[pgconn.PgConn]) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.conns, p)
+	}, p)
+	r.conns[p] = generation
+}
+
+// fingerprint identifies the configuration loaded from a binding, including the certificate entries that the TLS
+// configuration is built from.
+func fingerprint(binding bindings.Binding, c *Config) string {
+	h := sha256.New()
+
+	write := func(s string) {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+
+	write(c.Host)
+	write(c.Port)
+	write(c.Database)
+	write(c.Username)
+	write(c.Password)
+	write(c.SSLMode)
+
+	var keys []string
+	for k := range c.Options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		write(k)
+		write(c.Options[k])
+	}
+
+	for _, k := range []string{bindings.CACertificate, bindings.Certificate, bindings.PrivateKey} {
+		b, _ := binding.GetAsBytes(k)
+		write(string(b))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,340 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgresql_test
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgproto3/v2"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/nebhale/client-go/bindings"
+	"github.com/nebhale/client-go/bindings/bindingstest"
+	"github.com/nebhale/client-go/postgresql"
+)
+
+// fakeServer accepts PostgreSQL connections with cleartext password authentication and records the passwords they
+// authenticate with and the application names they start with.
+type fakeServer struct {
+	listener net.Listener
+
+	mu               sync.Mutex
+	passwords        []string
+	applicationNames []string
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	s := &fakeServer{listener: l}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(c)
+		}
+	}()
+
+	return s
+}
+
+func (s *fakeServer) serve(c net.Conn) {
+	defer c.Close()
+
+	b := pgproto3.NewBackend(pgproto3.NewChunkReader(c), c)
+	m, err := b.ReceiveStartupMessage()
+	if err != nil {
+		return
+	}
+	if sm, ok := m.(*pgproto3.StartupMessage); ok {
+		s.mu.Lock()
+		s.applicationNames = append(s.applicationNames, sm.Parameters["application_name"])
+		s.mu.Unlock()
+	}
+
+	if err := b.Send(&pgproto3.AuthenticationCleartextPassword{}); err != nil {
+		return
+	}
+	if err := b.SetAuthType(pgproto3.AuthTypeCleartextPassword); err != nil {
+		return
+	}
+
+	m, err = b.Receive()
+	if err != nil {
+		return
+	}
+	p, ok := m.(*pgproto3.PasswordMessage)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	s.passwords = append(s.passwords, p.Password)
+	s.mu.Unlock()
+
+	for _, m := range []pgproto3.BackendMessage{&pgproto3.AuthenticationOk{}, &pgproto3.BackendKeyData{}, &pgproto3.ReadyForQuery{TxStatus: 'I'}} {
+		if err := b.Send(m); err != nil {
+			return
+		}
+	}
+
+	for {
+		if _, err := b.Receive(); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeServer) Passwords() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.passwords...)
+}
+
+func (s *fakeServer) ApplicationNames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.applicationNames...)
+}
+
+func poolBinding(t *testing.T, s *fakeServer) *bindingstest.Binding {
+	t.Helper()
+
+	host, port, err := net.SplitHostPort(s.listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return bindingstest.NewRoot(t).Kubernetes().Binding("test-name").Entries(map[string]string{
+		"type":     "postgresql",
+		"host":     host,
+		"port":     port,
+		"username": "test-username",
+		"password": "test-password-1",
+		"sslmode":  "disable",
+	})
+}
+
+func poolConfig(t *testing.T, b bindings.Binding) *pgxpool.Config {
+	t.Helper()
+
+	c, err := postgresql.Load(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := c.PoolConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.LazyConnect = true
+
+	return cfg
+}
+
+func acquire(t *testing.T, pool *pgxpool.Pool) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	c, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Release()
+
+	// connections are returned to the pool asynchronously
+	for pool.Stat().AcquiredConns() > 0 {
+		if ctx.Err() != nil {
+			t.Fatal("connection was not released")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func Test_Reloader_BeforeConnect(t *testing.T) {
+	s := newFakeServer(t)
+	d := poolBinding(t, s)
+	b := bindings.ConfigTreeBinding{Root: d.Path()}
+
+	cfg := poolConfig(t, b)
+	(&postgresql.Reloader{Binding: b}).Install(cfg)
+
+	pool, err := pgxpool.ConnectConfig(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	acquire(t, pool)
+	d.Entry("password", "test-password-2")
+
+	// the idle connection is not recycled, so a second connection is forced by holding the first
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	c1, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c1.Release()
+	c2.Release()
+
+	if !reflect.DeepEqual(s.Passwords(), []string{"test-password-1", "test-password-2"}) {
+		t.Errorf("did not reload binding: %v", s.Passwords())
+	}
+}
+
+func Test_Reloader_Recycle(t *testing.T) {
+	s := newFakeServer(t)
+	d := poolBinding(t, s)
+	b := bindings.ConfigTreeBinding{Root: d.Path()}
+
+	cfg := poolConfig(t, b)
+	(&postgresql.Reloader{Binding: b, Recycle: true, Interval: time.Millisecond}).Install(cfg)
+
+	pool, err := pgxpool.ConnectConfig(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	acquire(t, pool)
+	acquire(t, pool)
+	d.Entry("password", "test-password-2")
+	time.Sleep(10 * time.Millisecond)
+	acquire(t, pool)
+	acquire(t, pool)
+
+	if !reflect.DeepEqual(s.Passwords(), []string{"test-password-1", "test-password-2"}) {
+		t.Errorf("did not recycle connection: %v", s.Passwords())
+	}
+}
+
+func Test_Reloader_Recycle_Options(t *testing.T) {
+	s := newFakeServer(t)
+	d := poolBinding(t, s).Entry("url", "postgres:///?application_name=test-application-1")
+	b := bindings.ConfigTreeBinding{Root: d.Path()}
+
+	cfg := poolConfig(t, b)
+	(&postgresql.Reloader{Binding: b, Recycle: true, Interval: time.Millisecond}).Install(cfg)
+
+	pool, err := pgxpool.ConnectConfig(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	acquire(t, pool)
+	d.Entry("url", "postgres:///?application_name=test-application-2")
+	time.Sleep(10 * time.Millisecond)
+	acquire(t, pool)
+
+	if !reflect.DeepEqual(s.ApplicationNames(), []string{"test-application-1", "test-application-2"}) {
+		t.Errorf("did not reload options: %v", s.ApplicationNames())
+	}
+}
+
+func Test_Reloader_Chain(t *testing.T) {
+	s := newFakeServer(t)
+	b := bindings.ConfigTreeBinding{Root: poolBinding(t, s).Path()}
+
+	var (
+		mu     sync.Mutex
+		called []string
+	)
+	record := func(hook string) {
+		mu.Lock()
+		defer mu.Unlock()
+		called = append(called, hook)
+	}
+
+	cfg := poolConfig(t, b)
+	cfg.BeforeConnect = func(context.Context, *pgx.ConnConfig) error {
+		record("BeforeConnect")
+		return nil
+	}
+	cfg.BeforeAcquire = func(context.Context, *pgx.Conn) bool {
+		record("BeforeAcquire")
+		return true
+	}
+	cfg.AfterRelease = func(*pgx.Conn) bool {
+		record("AfterRelease")
+		return true
+	}
+	(&postgresql.Reloader{Binding: b, Recycle: true}).Install(cfg)
+
+	pool, err := pgxpool.ConnectConfig(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	acquire(t, pool)
+	acquire(t, pool)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(called, []string{"BeforeConnect", "BeforeAcquire", "AfterRelease", "BeforeAcquire", "AfterRelease"}) {
+		t.Errorf("did not call existing hooks: %v", called)
+	}
+}
+
+func Test_Reloader_Invalid(t *testing.T) {
+	s := newFakeServer(t)
+	d := poolBinding(t, s)
+	b := bindings.ConfigTreeBinding{Root: d.Path()}
+
+	cfg := poolConfig(t, b)
+	(&postgresql.Reloader{Binding: b}).Install(cfg)
+	d.Entry("type", "mysql")
+
+	pool, err := pgxpool.ConnectConfig(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := pool.Acquire(ctx); err == nil || !strings.Contains(err.Error(), "not postgresql") {
+		t.Errorf("does not identify invalid binding: %v", err)
+	}
+}
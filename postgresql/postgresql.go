@@ -0,0 +1,333 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgresql
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jackc/pgx/v4/stdlib"
+	"github.com/nebhale/client-go/bindings"
+	"github.com/nebhale/client-go/internal"
+)
+
+// Type is the type of PostgreSQL bindings.
+const Type = "postgresql"
+
+// Config is the connection configuration described by a PostgreSQL binding.
+type Config struct {
+
+	// Host is the host, or comma separated hosts, of the server.
+	Host string `binding:"host"`
+
+	// Port is the port, or comma separated ports, of the server.
+	Port string `binding:"port"`
+
+	// Database is the name of the database.
+	Database string `binding:"database"`
+
+	// Username is the user to connect as.
+	Username string `binding:"username"`
+
+	// Password is the password of the user.
+	Password string `binding:"password"`
+
+	// SSLMode is the libpq sslmode to connect with.
+	SSLMode string `binding:"sslmode"`
+
+	// Options are additional libpq connection parameters.
+	Options map[string]string `binding:"-"`
+
+	// TLS is the TLS configuration built from the certificate entries of the binding, or nil if there are none.
+	TLS *tls.Config `binding:"-"`
+
+	// SSLRootCert, SSLCert, and SSLKey are the paths of the certificate entries of the binding, if the binding is read
+	// from the filesystem.
+	SSLRootCert string `binding:"-"`
+	SSLCert     string `binding:"-"`
+	SSLKey      string `binding:"-"`
+}
+
+// Load reads the connection configuration from a binding of type postgresql.  The configuration may be described by
+// a url (or uri) entry, by the host, port, database, username, password, and sslmode entries, or by both, in which
+// case the discrete entries take precedence.  If the binding contains ca.crt, tls.crt, or tls.key entries, they are
+// used to configure TLS, and if the binding does not specify an sslmode, verify-full is assumed, or verify-ca if there
+// is no server name to verify because neither a server-name entry nor a host name, rather than an IP address or socket
+// directory, is given.
+func Load(binding bindings.Binding) (*Config, error) {
+	t, err := bindings.GetType(binding)
+	if err != nil {
+		return nil, fmt.Errorf("binding %s: %w", binding.GetName(), err)
+	}
+	if !strings.EqualFold(t, Type) {
+		return nil, fmt.Errorf("binding %s is of type %s, not %s", binding.GetName(), t, Type)
+	}
+
+	var c Config
+	if err := bindings.Unmarshal(binding, &c); err != nil {
+		return nil, err
+	}
+
+	u, ok := bindings.Get(binding, "url")
+	if !ok {
+		u, ok = bindings.Get(binding, "uri")
+	}
+	if ok {
+		if err := c.merge(u); err != nil {
+			return nil, fmt.Errorf("binding %s: invalid url: %w", binding.GetName(), err)
+		}
+	}
+
+	if c.Host == "" {
+		return nil, fmt.Errorf("binding %s must contain either a host or a url", binding.GetName())
+	}
+
+	if bindings.HasTLS(binding) {
+		if c.TLS, err = bindings.TLSConfig(binding); err != nil {
+			return nil, err
+		}
+	}
+
+	if ct, ok := binding.(bindings.ConfigTreeBinding); ok {
+		if _, ok := binding.GetAsBytes(bindings.CACertificate); ok {
+			c.SSLRootCert = filepath.Join(ct.Root, bindings.CACertificate)
+		}
+		if _, ok := binding.GetAsBytes(bindings.Certificate); ok {
+			c.SSLCert = filepath.Join(ct.Root, bindings.Certificate)
+			c.SSLKey = filepath.Join(ct.Root, bindings.PrivateKey)
+		}
+	}
+
+	return &c, nil
+}
+
+// DSN returns the configuration as a libpq keyword/value connection string suitable for use with database/sql.  The
+// certificate entries of the binding are only included if the binding is read from the filesystem.
+func (c *Config) DSN() string {
+	var s []string
+
+	add := func(key string, value string) {
+		if value != "" {
+			s = append(s, fmt.Sprintf("%s=%s", key, quote(value)))
+		}
+	}
+
+	add("host", c.Host)
+	add("port", c.Port)
+	add("dbname", c.Database)
+	add("user", c.Username)
+	add("password", c.Password)
+	add("sslmode", c.sslMode())
+	add("sslrootcert", c.SSLRootCert)
+	add("sslcert", c.SSLCert)
+	add("sslkey", c.SSLKey)
+
+	keys := make([]string, 0, len(c.Options))
+	for k := range c.Options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		add(k, c.Options[k])
+	}
+
+	return strings.Join(s, " ")
+}
+
+// ConnConfig returns the configuration as a *pgx.ConnConfig.  TLS is configured from the certificate entries of the
+// binding according to the sslmode.
+func (c *Config) ConnConfig() (*pgx.ConnConfig, error) {
+	cfg, err := pgx.ParseConfig(c.tlsDSN())
+	if err != nil {
+		return nil, err
+	}
+
+	c.configureTLS(&cfg.Config)
+	return cfg, nil
+}
+
+// PoolConfig returns the configuration as a *pgxpool.Config.  TLS is configured from the certificate entries of the
+// binding according to the sslmode.
+func (c *Config) PoolConfig() (*pgxpool.Config, error) {
+	cfg, err := pgxpool.ParseConfig(c.tlsDSN())
+	if err != nil {
+		return nil, err
+	}
+
+	c.configureTLS(&cfg.ConnConfig.Config)
+	return cfg, nil
+}
+
+// Connector returns a driver.Connector for use with sql.OpenDB.  The configuration, including TLS, is loaded from the
+// binding on every call to Connect so that new connections in the pool use rotated credentials.
+func Connector(binding bindings.Binding) driver.Connector {
+	return connector{binding: binding}
+}
+
+type connector struct {
+	binding bindings.Binding
+}
+
+func (c connector) Connect(ctx context.Context) (driver.Conn, error) {
+	cfg, err := Load(c.binding)
+	if err != nil {
+		return nil, err
+	}
+
+	cc, err := cfg.ConnConfig()
+	if err != nil {
+		return nil, fmt.Errorf("binding %s: %w", c.binding.GetName(), err)
+	}
+
+	return stdlib.GetConnector(*cc).Connect(ctx)
+}
+
+func (c connector) Driver() driver.Driver {
+	return stdlib.GetDefaultDriver()
+}
+
+// tlsDSN returns a connection string that leaves TLS material to configureTLS.  As with libpq, sslmode=require
+// verifies the server's certificate chain if certificate authorities are present.
+func (c *Config) tlsDSN() string {
+	d := *c
+	d.SSLRootCert, d.SSLCert, d.SSLKey = "", "", ""
+
+	if d.TLS != nil && d.TLS.RootCAs != nil && d.sslMode() == "require" {
+		d.SSLMode = "verify-ca"
+	}
+
+	return d.DSN()
+}
+
+// sslMode returns the sslmode, defaulting to verification of the server if TLS is configured from the binding.
+func (c *Config) sslMode() string {
+	if c.SSLMode != "" || c.TLS == nil {
+		return c.SSLMode
+	}
+
+	if c.TLS.ServerName != "" {
+		return "verify-full"
+	}
+
+	for _, h := range strings.Split(c.Host, ",") {
+		if net.ParseIP(h) != nil || strings.HasPrefix(h, "/") {
+			return "verify-ca"
+		}
+	}
+
+	return "verify-full"
+}
+
+func (c *Config) configureTLS(cfg *pgconn.Config) {
+	if c.TLS == nil {
+		return
+	}
+
+	apply := func(t *tls.Config) {
+		if t == nil {
+			return
+		}
+
+		if c.TLS.RootCAs != nil {
+			t.RootCAs = c.TLS.RootCAs
+		}
+
+		t.Certificates = c.TLS.Certificates
+		t.GetClientCertificate = c.TLS.GetClientCertificate
+
+		if c.TLS.ServerName != "" {
+			t.ServerName = c.TLS.ServerName
+		}
+
+		if c.TLS.InsecureSkipVerify {
+			t.InsecureSkipVerify = true
+			t.VerifyPeerCertificate = nil
+		}
+	}
+
+	apply(cfg.TLSConfig)
+	for _, f := range cfg.Fallbacks {
+		apply(f.TLSConfig)
+	}
+}
+
+// merge fills the fields that are not already set from a postgres:// URL.
+func (c *Config) merge(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "postgres" && u.Scheme != "postgresql" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+
+	var hosts, ports []string
+	for _, h := range strings.Split(u.Host, ",") {
+		if h == "" {
+			continue
+		}
+
+		if host, port, err := net.SplitHostPort(h); err == nil {
+			hosts, ports = append(hosts, host), append(ports, port)
+		} else {
+			hosts = append(hosts, h)
+		}
+	}
+
+	internal.SetIfEmpty(&c.Host, strings.Join(hosts, ","))
+	if len(ports) == len(hosts) {
+		internal.SetIfEmpty(&c.Port, strings.Join(ports, ","))
+	}
+	internal.SetIfEmpty(&c.Database, strings.TrimPrefix(u.Path, "/"))
+
+	if u.User != nil {
+		internal.SetIfEmpty(&c.Username, u.User.Username())
+		if p, ok := u.User.Password(); ok {
+			internal.SetIfEmpty(&c.Password, p)
+		}
+	}
+
+	for k, v := range u.Query() {
+		if k == "sslmode" {
+			internal.SetIfEmpty(&c.SSLMode, v[0])
+			continue
+		}
+
+		if c.Options == nil {
+			c.Options = make(map[string]string)
+		}
+		c.Options[k] = v[0]
+	}
+
+	return nil
+}
+
+func quote(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
@@ -0,0 +1,253 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package redis
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/nebhale/client-go/bindings"
+)
+
+// Type is the type of Redis bindings.
+const Type = "redis"
+
+// DefaultPort is the port used for nodes that do not specify one.
+const DefaultPort = "6379"
+
+// DefaultSentinelPort is the port used for Sentinel nodes that do not specify one.
+const DefaultSentinelPort = "26379"
+
+// Topology is the deployment a Redis binding describes.
+type Topology uint8
+
+const (
+	// Single is a single Redis node described by the host and port entries.
+	Single Topology = iota
+
+	// Sentinel is a set of Redis Sentinel nodes described by the sentinel.master and sentinel.nodes entries.
+	Sentinel
+
+	// Cluster is a Redis Cluster described by the cluster.nodes entry.
+	Cluster
+)
+
+func (t Topology) String() string {
+	switch t {
+	case Single:
+		return "Single"
+	case Sentinel:
+		return "Sentinel"
+	case Cluster:
+		return "Cluster"
+	default:
+		return fmt.Sprintf("Topology(%d)", t)
+	}
+}
+
+// Options are the client options described by a Redis binding, independent of any particular client library.
+type Options struct {
+
+	// Topology is the deployment the binding describes.
+	Topology Topology `binding:"-"`
+
+	// Host is the host of a Single node.
+	Host string `binding:"host"`
+
+	// Port is the port of a Single node.
+	Port string `binding:"port"`
+
+	// Username is the ACL user to authenticate as.
+	Username string `binding:"username"`
+
+	// Password is the password to authenticate with.
+	Password string `binding:"password"`
+
+	// Database is the number of the database to select.
+	Database int `binding:"database"`
+
+	// SSL is whether to connect with TLS.  It is implied if the binding contains certificate entries.
+	SSL bool `binding:"ssl"`
+
+	// ClientName is the name the client sets for its connections.
+	ClientName string `binding:"client-name"`
+
+	// Sentinel is the Sentinel configuration of the binding.
+	Sentinel SentinelOptions `binding:"sentinel"`
+
+	// Cluster is the Cluster configuration of the binding.
+	Cluster ClusterOptions `binding:"cluster"`
+
+	// TLS is the TLS configuration if SSL is set, or nil otherwise.
+	TLS *tls.Config `binding:"-"`
+}
+
+// SentinelOptions are the Sentinel entries of a Redis binding.
+type SentinelOptions struct {
+
+	// Master is the name of the master the Sentinel nodes monitor.
+	Master string `binding:"master"`
+
+	// Nodes are the addresses of the Sentinel nodes, read from a comma or newline separated list.
+	Nodes []string `binding:"nodes"`
+
+	// Password is the password to authenticate with the Sentinel nodes, if different from the Redis nodes.
+	Password string `binding:"password"`
+}
+
+// ClusterOptions are the Cluster entries of a Redis binding.
+type ClusterOptions struct {
+
+	// Nodes are the addresses of the seed Cluster nodes, read from a comma or newline separated list.
+	Nodes []string `binding:"nodes"`
+}
+
+// Load reads the client options from a binding of type redis.  The topology is Sentinel if the binding contains
+// sentinel.master or sentinel.nodes entries, Cluster if it contains a cluster.nodes entry, and Single otherwise.  Node
+// addresses without a port are given the default port for the topology.  The returned error names any keys the
+// topology requires that are missing.
+func Load(binding bindings.Binding) (*Options, error) {
+	t, err := bindings.GetType(binding)
+	if err != nil {
+		return nil, fmt.Errorf("binding %s: %w", binding.GetName(), err)
+	}
+	if !strings.EqualFold(t, Type) {
+		return nil, fmt.Errorf("binding %s is of type %s, not %s", binding.GetName(), t, Type)
+	}
+
+	var o Options
+	if err := bindings.Unmarshal(binding, &o); err != nil {
+		return nil, err
+	}
+
+	sentinel := o.Sentinel.Master != "" || len(o.Sentinel.Nodes) > 0
+	cluster := len(o.Cluster.Nodes) > 0
+
+	var missing []string
+	switch {
+	case sentinel && cluster:
+		return nil, fmt.Errorf("binding %s describes both a Sentinel and a Cluster topology", binding.GetName())
+	case sentinel:
+		o.Topology = Sentinel
+		if o.Sentinel.Master == "" {
+			missing = append(missing, "sentinel.master")
+		}
+		if len(o.Sentinel.Nodes) == 0 {
+			missing = append(missing, "sentinel.nodes")
+		}
+		o.Sentinel.Nodes = withPorts(o.Sentinel.Nodes, DefaultSentinelPort)
+	case cluster:
+		o.Topology = Cluster
+		o.Cluster.Nodes = withPorts(o.Cluster.Nodes, DefaultPort)
+	default:
+		o.Topology = Single
+		if o.Host == "" {
+			missing = append(missing, "host")
+		}
+		if o.Port == "" {
+			o.Port = DefaultPort
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("binding %s describes a %s topology but is missing %s", binding.GetName(), o.Topology,
+			strings.Join(missing, ", "))
+	}
+
+	if bindings.HasTLS(binding) {
+		o.SSL = true
+		if o.TLS, err = bindings.TLSConfig(binding); err != nil {
+			return nil, err
+		}
+	} else if o.SSL {
+		o.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if o.TLS != nil && o.Topology == Single && o.TLS.ServerName == "" {
+		o.TLS.ServerName = o.Host
+	}
+
+	return &o, nil
+}
+
+// Addrs returns the addresses the client connects to first: the Single node, the Sentinel nodes, or the seed Cluster
+// nodes.
+func (o *Options) Addrs() []string {
+	switch o.Topology {
+	case Sentinel:
+		return o.Sentinel.Nodes
+	case Cluster:
+		return o.Cluster.Nodes
+	default:
+		return []string{net.JoinHostPort(o.Host, o.Port)}
+	}
+}
+
+// URL returns the options as a redis:// URL, or a rediss:// URL if SSL is set.  The first address is the host of the
+// URL and any others are given as addr parameters.  The database, client name, and Sentinel master are given as the
+// path and the client_name and master_name parameters.
+func (o *Options) URL() *url.URL {
+	u := url.URL{Scheme: "redis"}
+	if o.SSL {
+		u.Scheme = "rediss"
+	}
+
+	if o.Username != "" || o.Password != "" {
+		u.User = url.UserPassword(o.Username, o.Password)
+	}
+
+	q := url.Values{}
+
+	a := o.Addrs()
+	if len(a) > 0 {
+		u.Host = a[0]
+		for _, r := range a[1:] {
+			q.Add("addr", r)
+		}
+	}
+
+	if o.Topology != Cluster {
+		u.Path = "/" + strconv.Itoa(o.Database)
+	}
+	if o.ClientName != "" {
+		q.Set("client_name", o.ClientName)
+	}
+	if o.Topology == Sentinel {
+		q.Set("master_name", o.Sentinel.Master)
+	}
+
+	u.RawQuery = q.Encode()
+	return &u
+}
+
+// withPorts adds a default port to addresses that do not have one.
+func withPorts(addrs []string, port string) []string {
+	var a []string
+
+	for _, r := range addrs {
+		if _, _, err := net.SplitHostPort(r); err != nil {
+			r = net.JoinHostPort(r, port)
+		}
+		a = append(a, r)
+	}
+
+	return a
+}
@@ -0,0 +1,243 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package render
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"text/template"
+
+	"github.com/nebhale/client-go/bindings"
+)
+
+// DefaultMode is the permissions of rendered files if none are specified.
+const DefaultMode fs.FileMode = 0600
+
+// Data is the data that templates are executed with.
+type Data struct {
+
+	// Bindings are the bindings indexed by name.
+	Bindings map[string]bindings.Binding
+
+	all []bindings.Binding
+}
+
+// NewData creates the data for a collection of bindings.
+func NewData(b []bindings.Binding) Data {
+	d := Data{Bindings: make(map[string]bindings.Binding, len(b)), all: b}
+	for _, c := range b {
+		d.Bindings[c.GetName()] = c
+	}
+
+	return d
+}
+
+// All returns all the bindings.
+func (d Data) All() []bindings.Binding {
+	return d.all
+}
+
+// Find returns the binding with a given name, or nil if there is none.  Comparison is case-insensitive.
+func (d Data) Find(name string) bindings.Binding {
+	b, _ := bindings.Find(d.all, name)
+	return b
+}
+
+// Filter returns the bindings with a given type.  Comparison is case-insensitive.
+func (d Data) Filter(bindingType string) []bindings.Binding {
+	return bindings.Filter(d.all, bindingType)
+}
+
+// FilterWithProvider returns the bindings with a given type and provider.  If type or provider are empty, the result is
+// not filtered on that argument.  Comparisons are case-insensitive.
+func (d Data) FilterWithProvider(bindingType string, provider string) []bindings.Binding {
+	return bindings.FilterWithProvider(d.all, bindingType, provider)
+}
+
+// Funcs returns the functions available to templates:
+//
+//	get BINDING KEY           the trimmed value of an entry, or "" if it is missing
+//	getBytes BINDING KEY      the raw value of an entry, or nil if it is missing
+//	base64 VALUE              the standard base64 encoding of a string or []byte
+//	required MESSAGE VALUE    VALUE, or an error with MESSAGE if VALUE is empty
+//	default DEFAULT VALUE     VALUE, or DEFAULT if VALUE is empty
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"get": func(binding bindings.Binding, key string) string {
+			if binding == nil {
+				return ""
+			}
+			v, _ := bindings.Get(binding, key)
+			return v
+		},
+		"getBytes": func(binding bindings.Binding, key string) []byte {
+			if binding == nil {
+				return nil
+			}
+			v, _ := binding.GetAsBytes(key)
+			return v
+		},
+		"base64": func(value any) (string, error) {
+			switch v := value.(type) {
+			case string:
+				return base64.StdEncoding.EncodeToString([]byte(v)), nil
+			case []byte:
+				return base64.StdEncoding.EncodeToString(v), nil
+			default:
+				return "", fmt.Errorf("base64 requires a string or []byte, not %T", value)
+			}
+		},
+		"required": func(message string, value any) (any, error) {
+			if isEmpty(value) {
+				return nil, fmt.Errorf("%s", message)
+			}
+			return value, nil
+		},
+		"default": func(def any, value any) any {
+			if isEmpty(value) {
+				return def
+			}
+			return value
+		},
+	}
+}
+
+// New parses a template with the Funcs available.  Referencing a missing map key, such as an unknown name in
+// .Bindings, is an error.
+func New(name string, text string) (*template.Template, error) {
+	return template.New(name).Funcs(Funcs()).Option("missingkey=error").Parse(text)
+}
+
+// Execute executes a template with the Data for a collection of bindings.
+func Execute(w io.Writer, t *template.Template, b []bindings.Binding) error {
+	return t.Execute(w, NewData(b))
+}
+
+// Renderer renders a template to a file.
+type Renderer struct {
+
+	// Template is the template to render.
+	Template *template.Template
+
+	// Path is the path of the rendered file.
+	Path string
+
+	// Mode is the permissions of the rendered file.  If zero, DefaultMode is used.
+	Mode fs.FileMode
+
+	// OnError is called with errors that occur when re-rendering in Watch.  If nil, those errors are ignored and the
+	// previously rendered file is left in place.
+	OnError func(error)
+}
+
+// Render executes the template with a collection of bindings and atomically replaces the file with the result.  The
+// file is not rewritten if its content would not change.
+func (r Renderer) Render(b []bindings.Binding) error {
+	var out bytes.Buffer
+	if err := Execute(&out, r.Template, b); err != nil {
+		return fmt.Errorf("unable to render %s: %w", r.Path, err)
+	}
+
+	mode := r.Mode
+	if mode == 0 {
+		mode = DefaultMode
+	}
+
+	if existing, err := os.ReadFile(r.Path); err == nil && bytes.Equal(existing, out.Bytes()) {
+		if fi, err := os.Stat(r.Path); err == nil && fi.Mode().Perm() == mode.Perm() {
+			return nil
+		}
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(r.Path), "."+filepath.Base(r.Path)+".*")
+	if err != nil {
+		return fmt.Errorf("unable to render %s: %w", r.Path, err)
+	}
+	defer os.Remove(f.Name())
+
+	if err := f.Chmod(mode); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("unable to render %s: %w", r.Path, err)
+	}
+
+	if _, err := f.Write(out.Bytes()); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("unable to render %s: %w", r.Path, err)
+	}
+
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("unable to render %s: %w", r.Path, err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("unable to render %s: %w", r.Path, err)
+	}
+
+	if err := os.Rename(f.Name(), r.Path); err != nil {
+		return fmt.Errorf("unable to render %s: %w", r.Path, err)
+	}
+
+	return nil
+}
+
+// Watch renders the bindings in the root of a Watcher, and re-renders them whenever they change, until the context is
+// cancelled.  An error is returned if the initial render fails.
+func (r Renderer) Watch(ctx context.Context, w bindings.Watcher) error {
+	if err := r.Render(bindings.From(w.Root)); err != nil {
+		return err
+	}
+
+	events := w.Watch(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case _, ok := <-events:
+			if !ok {
+				return ctx.Err()
+			}
+
+			if err := r.Render(bindings.From(w.Root)); err != nil && r.OnError != nil {
+				r.OnError(err)
+			}
+		}
+	}
+}
+
+func isEmpty(value any) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
@@ -0,0 +1,296 @@
+/*
+ * Copyright 2021 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package render_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/nebhale/client-go/bindings"
+	"github.com/nebhale/client-go/bindings/bindingstest"
+	"github.com/nebhale/client-go/render"
+)
+
+var testBindings = []bindings.Binding{
+	bindings.MapBinding{
+		Name: "test-name-1",
+		Content: map[string][]byte{
+			"type":     []byte("test-type-1"),
+			"provider": []byte("test-provider-1"),
+			"username": []byte("test-username\n"),
+			"password": []byte("test-password"),
+		},
+	},
+	bindings.MapBinding{
+		Name: "test-name-2",
+		Content: map[string][]byte{
+			"type":     []byte("test-type-1"),
+			"provider": []byte("test-provider-2"),
+		},
+	},
+}
+
+func execute(t *testing.T, text string) (string, error) {
+	t.Helper()
+
+	tmpl, err := render.New("test", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	err = render.Execute(&out, tmpl, testBindings)
+	return out.String(), err
+}
+
+func Test_Execute_Bindings(t *testing.T) {
+	s, err := execute(t, `{{ get (index .Bindings "test-name-1") "username" }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "test-username" {
+		t.Errorf("returned the wrong value: %q", s)
+	}
+}
+
+func Test_Execute_MissingBinding(t *testing.T) {
+	if _, err := execute(t, `{{ .Bindings.missing }}`); err == nil {
+		t.Errorf("does not identify missing binding")
+	}
+}
+
+func Test_Execute_All(t *testing.T) {
+	s, err := execute(t, `{{ len .All }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "2" {
+		t.Errorf("returned the wrong value: %q", s)
+	}
+}
+
+func Test_Execute_Find(t *testing.T) {
+	s, err := execute(t, `{{ with .Find "TEST-NAME-1" }}{{ get . "password" }}{{ end }}{{ with .Find "missing" }}found{{ end }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "test-password" {
+		t.Errorf("returned the wrong value: %q", s)
+	}
+}
+
+func Test_Execute_Filter(t *testing.T) {
+	s, err := execute(t, `{{ range .Filter "test-type-1" }}{{ .GetName }} {{ end }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "test-name-1 test-name-2 " {
+		t.Errorf("returned the wrong value: %q", s)
+	}
+}
+
+func Test_Execute_FilterWithProvider(t *testing.T) {
+	s, err := execute(t, `{{ range .FilterWithProvider "test-type-1" "test-provider-2" }}{{ .GetName }}{{ end }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "test-name-2" {
+		t.Errorf("returned the wrong value: %q", s)
+	}
+}
+
+func Test_Execute_GetBytes(t *testing.T) {
+	s, err := execute(t, `{{ printf "%q" (getBytes (.Find "test-name-1") "username") }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != `"test-username\n"` {
+		t.Errorf("returned the wrong value: %q", s)
+	}
+}
+
+func Test_Execute_Base64(t *testing.T) {
+	s, err := execute(t, `{{ with .Find "test-name-1" }}{{ get . "password" | base64 }} {{ getBytes . "password" | base64 }}{{ end }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "dGVzdC1wYXNzd29yZA== dGVzdC1wYXNzd29yZA==" {
+		t.Errorf("returned the wrong value: %q", s)
+	}
+}
+
+func Test_Execute_Required(t *testing.T) {
+	if _, err := execute(t, `{{ get (.Find "test-name-1") "missing" | required "missing is required" }}`); err == nil || !strings.Contains(err.Error(), "missing is required") {
+		t.Errorf("does not identify missing value: %v", err)
+	}
+
+	s, err := execute(t, `{{ get (.Find "test-name-1") "password" | required "password is required" }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "test-password" {
+		t.Errorf("returned the wrong value: %q", s)
+	}
+}
+
+func Test_Execute_Default(t *testing.T) {
+	s, err := execute(t, `{{ get (.Find "missing") "port" | default "5432" }} {{ get (.Find "test-name-1") "password" | default "x" }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "5432 test-password" {
+		t.Errorf("returned the wrong value: %q", s)
+	}
+}
+
+func Test_Renderer_Render(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.conf")
+	r := render.Renderer{
+		Template: template.Must(render.New("test", `password={{ get (.Find "test-name-1") "password" }}`)),
+		Path:     path,
+	}
+
+	if err := r.Render(testBindings); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "password=test-password" {
+		t.Errorf("returned the wrong value: %q", b)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != render.DefaultMode {
+		t.Errorf("returned the wrong mode: %v", fi.Mode())
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("left temporary files: %v", entries)
+	}
+}
+
+func Test_Renderer_Render_Mode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.conf")
+	r := render.Renderer{
+		Template: template.Must(render.New("test", `test`)),
+		Path:     path,
+		Mode:     0640,
+	}
+
+	if err := r.Render(testBindings); err != nil {
+		t.Fatal(err)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0640 {
+		t.Errorf("returned the wrong mode: %v", fi.Mode())
+	}
+}
+
+func Test_Renderer_Render_Error(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.conf")
+	if err := os.WriteFile(path, []byte("previous"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r := render.Renderer{
+		Template: template.Must(render.New("test", `{{ get (.Find "missing") "password" | required "password is required" }}`)),
+		Path:     path,
+	}
+
+	if err := r.Render(testBindings); err == nil {
+		t.Errorf("does not identify template error")
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "previous" {
+		t.Errorf("replaced file after error: %q", b)
+	}
+}
+
+func Test_Renderer_Watch(t *testing.T) {
+	root := bindingstest.NewRoot(t).Kubernetes()
+	b := root.Binding("test-name-1").Type("test-type-1").Entry("password", "test-password-1")
+
+	path := filepath.Join(t.TempDir(), "test.conf")
+	r := render.Renderer{
+		Template: template.Must(render.New("test", `{{ get (.Find "test-name-1") "password" }}`)),
+		Path:     path,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Watch(ctx, bindings.Watcher{Root: root.Path(), Interval: 10 * time.Millisecond})
+	}()
+
+	waitForFile(t, path, "test-password-1")
+	b.Entry("password", "test-password-2")
+	waitForFile(t, path, "test-password-2")
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("returned the wrong error: %v", err)
+	}
+}
+
+func Test_Renderer_Watch_Error(t *testing.T) {
+	r := render.Renderer{
+		Template: template.Must(render.New("test", `{{ .Bindings.missing }}`)),
+		Path:     filepath.Join(t.TempDir(), "test.conf"),
+	}
+
+	if err := r.Watch(context.Background(), bindings.Watcher{Root: bindingstest.NewRoot(t).Path()}); err == nil {
+		t.Errorf("does not identify initial render error")
+	}
+}
+
+func waitForFile(t *testing.T, path string, expected string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if b, err := os.ReadFile(path); err == nil && string(b) == expected {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("file was not rendered with %q", expected)
+}